@@ -72,8 +72,8 @@ if __name__ == '__main__':
     if not baseDir:
         print 'Environment variable SR_CODE_BASE is not set'
     
-    objDescriptors = [ baseDir + '/snaproute/src/models/objects/' + 'genObjectConfig.json',
-                       baseDir + '/snaproute/src/models/actions/' + 'genObjectAction.json']
+    objDescriptors = [ baseDir + '/snaproute/src/models/objects/' + 'genObjectConfigIndex.json',
+                       baseDir + '/snaproute/src/models/actions/' + 'genObjectActionIndex.json']
     attrDescriptorsLocation = baseDir+'/reltools/codegentools/._genInfo/'
     outputDir = baseDir+'/snaproute/src/flexSdk/py/'
     gen = apiGenie( outputDir, objDescriptors, attrDescriptorsLocation)
@@ -1,7 +1,6 @@
 #!/bin/bash
-go run *.go 
+go run . "$@"
 #for srcFile in `cat dbIffiles.txt`;
 #do
 #	go fmt $srcFile
 #done    
-
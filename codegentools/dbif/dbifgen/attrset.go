@@ -0,0 +1,55 @@
+package dbifgen
+
+import (
+	"bytes"
+	"strconv"
+)
+
+// attrIdName returns the name of the generated constant identifying the attribute attrName
+func (obj *ObjectInfoJson) attrIdName(attrName string) string {
+	return obj.ObjName + "Attr" + attrName
+}
+
+// WriteAttrSetFcns writes the attribute ids of a config object and the AttrSet variants of the
+// functions that take or return the attributes as a []bool. The id of an attribute is its index
+// in those slices.
+func (obj *ObjectInfoJson) WriteAttrSetFcns(buf *bytes.Buffer, attrMap []ObjectMemberAndInfo, objMap map[string]ObjectInfoJson) {
+	var lines []string
+	namesVar := "attrNames" + obj.ObjName
+	lines = append(lines, "\nconst (\n")
+	for idx, attrInfo := range attrMap {
+		lines = append(lines, obj.attrIdName(attrInfo.MemberName)+" AttrId = "+strconv.Itoa(idx)+"\n")
+	}
+	lines = append(lines, ")\n")
+	lines = append(lines, "\nvar "+namesVar+" = []string{\n")
+	for _, attrInfo := range attrMap {
+		lines = append(lines, strconv.Quote(attrInfo.MemberName)+",\n")
+	}
+	lines = append(lines, "}\n")
+
+	lines = append(lines, `
+		// NewAttrSet returns a set of attributes of `+obj.ObjName+` holding ids
+		func (obj `+obj.ObjName+`) NewAttrSet(ids ...AttrId) AttrSet {
+			attrs := newAttrSet(`+namesVar+`)
+			for _, id := range ids {
+				attrs.Add(id)
+			}
+			return attrs
+		}
+
+		func (obj `+obj.ObjName+`) UpdateObjectInDbAttrs(inObj ConfigObj, attrs AttrSet, dbHdl redis.Conn) error {
+			return obj.UpdateObjectInDb(inObj, attrs.Bools(), dbHdl)
+		}
+
+		func (obj `+obj.ObjName+`) MergeDbAndConfigObjAttrs(dbObj ConfigObj, attrs AttrSet) (ConfigObj, error) {
+			return obj.MergeDbAndConfigObj(dbObj, attrs.Bools())
+		}
+
+		func (obj `+obj.ObjName+`) MergeDbAndConfigObjForPatchUpdateAttrs(dbObj ConfigObj, patchOpInfoSlice []PatchOpInfo) (ConfigObj, AttrSet, error) {
+			return obj.applyPatchOpInfo(dbObj, patchOpInfoSlice)
+		}
+		`)
+	for _, line := range lines {
+		buf.WriteString(line)
+	}
+}
@@ -0,0 +1,89 @@
+package dbifgen
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+const (
+	CHANGE_CREATE = "create"
+	CHANGE_UPDATE = "update"
+	CHANGE_DELETE = "delete"
+)
+
+// Name patterns of the files owned by the generator, in the source and genInfo directories.
+// Files matching these that a run does not produce are stale and get removed.
+var GeneratedSrcPatterns = []string{"gen_*dbif.go", "gen_*Objects_serializer.go", COMMON_FILE}
+var GeneratedInfoPatterns = []string{"*" + MEMBER_JSON, "*.extschema"}
+
+// FileChange describes what writing or removing a generated file does to the file on disk
+type FileChange struct {
+	Name string
+	Kind string
+	Old  []byte
+	New  []byte
+}
+
+// Diff returns the change in unified diff format
+func (change FileChange) Diff() string {
+	oldName, newName := change.Name, change.Name
+	switch change.Kind {
+	case CHANGE_CREATE:
+		oldName = "/dev/null"
+	case CHANGE_DELETE:
+		newName = "/dev/null"
+	}
+	return UnifiedDiff(oldName, newName, change.Old, change.New)
+}
+
+// CompareWithDisk compares generated files kept in memory with the files on disk. Files listed
+// in stale are reported as deleted. Files whose contents do not change are left out. The
+// changes are sorted by file name.
+func CompareWithDisk(files MemOutput, stale []string) ([]FileChange, error) {
+	var changes []FileChange
+	for name, data := range files {
+		old, err := ioutil.ReadFile(name)
+		if os.IsNotExist(err) {
+			changes = append(changes, FileChange{Name: name, Kind: CHANGE_CREATE, New: data})
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(old, data) {
+			changes = append(changes, FileChange{Name: name, Kind: CHANGE_UPDATE, Old: old, New: data})
+		}
+	}
+	for _, name := range stale {
+		old, err := ioutil.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, FileChange{Name: name, Kind: CHANGE_DELETE, Old: old})
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Name < changes[j].Name
+	})
+	return changes, nil
+}
+
+// FindStaleFiles returns the files in dir matching one of patterns that are not in generated
+func FindStaleFiles(dir string, patterns []string, generated map[string]bool) ([]string, error) {
+	var stale []string
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range matches {
+			if !generated[name] {
+				stale = append(stale, name)
+			}
+		}
+	}
+	sort.Strings(stale)
+	return stale, nil
+}
@@ -0,0 +1,286 @@
+package dbifgen
+
+import (
+	"bytes"
+	"path/filepath"
+)
+
+// Name of the file holding the code shared by the generated files of a package
+const COMMON_FILE = "gen_dbifCommon.go"
+
+var commonFileHeader = `
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+`
+
+// The objects package also applies patches, see commonPatchCode
+var commonObjectsFileHeader = `
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+)
+`
+
+// Code shared by the serializers of objects and actions
+var commonUnmarshalCode = `
+// LenientUnmarshal makes the generated unmarshal methods ignore the attributes of the json body
+// that the object does not have. Objects with lenientUnmarshal set in their config always do.
+var LenientUnmarshal = false
+
+// UnmarshalError is a json body that can not be decoded into an object. Attr is the attribute
+// at fault, if it is known.
+type UnmarshalError struct {
+	Object string
+	Attr   string
+	Msg    string
+	Err    error
+}
+
+func (e *UnmarshalError) Error() string {
+	msg := "Failed to unmarshal " + e.Object
+	if e.Attr != "" {
+		msg += " attribute " + e.Attr
+	}
+	return msg + ": " + e.Msg
+}
+
+func (e *UnmarshalError) Unwrap() error {
+	return e.Err
+}
+
+// unmarshalObject decodes body into obj, a pointer to an object of type objName
+func unmarshalObject(objName string, body []byte, obj interface{}, lenient bool) error {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	if !lenient && !LenientUnmarshal {
+		dec.DisallowUnknownFields()
+	}
+	err := dec.Decode(obj)
+	if err == nil {
+		if _, err = dec.Token(); err == io.EOF {
+			return nil
+		}
+		return &UnmarshalError{Object: objName, Msg: "unexpected data after the json object", Err: err}
+	}
+	uerr := &UnmarshalError{Object: objName, Msg: err.Error(), Err: err}
+	switch e := err.(type) {
+	case *json.UnmarshalTypeError:
+		uerr.Attr = e.Field
+		uerr.Msg = "expected " + e.Type.String() + ", got json " + e.Value
+	case *json.SyntaxError:
+		uerr.Msg = fmt.Sprintf("invalid json at offset %d: %s", e.Offset, e.Error())
+	default:
+		// The decoder reports unknown attributes as: json: unknown field "name"
+		if name := strings.TrimPrefix(err.Error(), "json: unknown field "); name != err.Error() {
+			uerr.Attr, _ = strconv.Unquote(name)
+			uerr.Msg = "unknown attribute"
+		}
+	}
+	return uerr
+}
+`
+
+// Code used by the UnmarshalObjectData, Validate and AttrSet functions of the config objects
+var commonObjectsCode = `
+// QueryParamError is a query parameter that can not be decoded into an attribute of an object
+type QueryParamError struct {
+	Object string
+	Attr   string
+	Value  string
+	Msg    string
+}
+
+func (e *QueryParamError) Error() string {
+	msg := "Invalid query parameter " + e.Attr + " of " + e.Object
+	if e.Value != "" {
+		msg += " " + strconv.Quote(e.Value)
+	}
+	return msg + ": " + e.Msg
+}
+
+// queryParamError returns the error for a value of a query parameter that does not parse as a
+// varType
+func queryParamError(objName string, attr string, value string, varType string, err error) error {
+	msg := err.Error()
+	if numErr, ok := err.(*strconv.NumError); ok {
+		msg = numErr.Err.Error()
+		if numErr.Err == strconv.ErrRange {
+			msg = "does not fit " + varType
+		}
+	}
+	return &QueryParamError{Object: objName, Attr: attr, Value: value, Msg: msg}
+}
+
+// queryParams returns the query parameters keyed by their lower case name. Names are case
+// insensitive, so the same name given twice in different cases is an error.
+func queryParams(objName string, queryMap map[string][]string) (map[string][]string, error) {
+	params := make(map[string][]string, len(queryMap))
+	for key, vals := range queryMap {
+		name := strings.ToLower(key)
+		if _, exist := params[name]; exist {
+			return nil, &QueryParamError{Object: objName, Attr: key, Msg: "given more than once"}
+		}
+		params[name] = vals
+	}
+	return params, nil
+}
+
+// AttrError is an invalid value of one attribute of an object
+type AttrError struct {
+	Attr string
+	Msg  string
+}
+
+func (e AttrError) Error() string {
+	return e.Attr + ": " + e.Msg
+}
+
+// ValidationError is returned by the Validate methods of the config objects. It names every
+// attribute that failed validation.
+type ValidationError struct {
+	Object string
+	Attrs  []AttrError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Attrs))
+	for _, attr := range e.Attrs {
+		msgs = append(msgs, attr.Error())
+	}
+	return "Invalid " + e.Object + ": " + strings.Join(msgs, "; ")
+}
+
+func (e *ValidationError) add(attr string, msg string) {
+	e.Attrs = append(e.Attrs, AttrError{Attr: attr, Msg: msg})
+}
+
+// Err returns e, or nil if no attribute failed validation
+func (e *ValidationError) Err() error {
+	if len(e.Attrs) == 0 {
+		return nil
+	}
+	return e
+}
+
+// AttrId identifies an attribute of an object. It is the index of the attribute in the []bool
+// attribute lists of the object functions.
+type AttrId int
+
+// attrFields returns the index sequences of the attributes of the object type typ, by attribute
+// id. The attributes of embedded structures are in place of the embedded field.
+func attrFields(typ reflect.Type) [][]int {
+	var fields [][]int
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.Anonymous {
+			fields = append(fields, []int{i})
+			continue
+		}
+		if field.Type.Kind() != reflect.Struct {
+			continue
+		}
+		for _, index := range attrFields(field.Type) {
+			fields = append(fields, append([]int{i}, index...))
+		}
+	}
+	return fields
+}
+
+// AttrSet is a set of attributes of one object. The NewAttrSet method of an object returns an
+// empty set of its attributes.
+type AttrSet struct {
+	names []string
+	bits  []uint64
+}
+
+func newAttrSet(names []string) AttrSet {
+	return AttrSet{names: names, bits: make([]uint64, (len(names)+63)/64)}
+}
+
+// Add adds the attribute id to the set. Ids the object does not have are ignored.
+func (set AttrSet) Add(id AttrId) {
+	if id >= 0 && int(id) < len(set.names) {
+		set.bits[id/64] |= 1 << uint(id%64)
+	}
+}
+
+// Has reports whether the attribute id is in the set
+func (set AttrSet) Has(id AttrId) bool {
+	return id >= 0 && int(id) < len(set.names) && set.bits[id/64]&(1<<uint(id%64)) != 0
+}
+
+// Len returns the number of attributes in the set
+func (set AttrSet) Len() int {
+	n := 0
+	for id := range set.names {
+		if set.Has(AttrId(id)) {
+			n++
+		}
+	}
+	return n
+}
+
+// Names returns the names of the attributes in the set, in declaration order
+func (set AttrSet) Names() []string {
+	var names []string
+	for id, name := range set.names {
+		if set.Has(AttrId(id)) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func (set AttrSet) String() string {
+	return "{" + strings.Join(set.Names(), ", ") + "}"
+}
+
+// addName adds the attribute name to the set. Names the object does not have are ignored.
+func (set AttrSet) addName(name string) {
+	for id, attrName := range set.names {
+		if attrName == name {
+			set.Add(AttrId(id))
+			return
+		}
+	}
+}
+
+// Bools returns the set as a []bool indexed by attribute id
+func (set AttrSet) Bools() []bool {
+	attrs := make([]bool, len(set.names))
+	for id := range set.names {
+		attrs[id] = set.Has(AttrId(id))
+	}
+	return attrs
+}
+`
+
+// writeCommonFile writes the code the generated files of the package depend on
+func (gen *Generator) writeCommonFile(model *Model) error {
+	var buf bytes.Buffer
+	var obj ObjectInfoJson
+	obj.WriteLicenseInfo(&buf)
+	buf.WriteString("package " + gen.PackageName + "\n")
+	buf.WriteString(commonCode(model.Actions))
+	return gen.writeFile(filepath.Join(gen.SrcDir, COMMON_FILE), buf.Bytes())
+}
+
+// commonCode returns the common file of an objects or actions package without its package clause
+func commonCode(actions bool) string {
+	if actions {
+		return commonFileHeader + commonUnmarshalCode
+	}
+	return commonObjectsFileHeader + commonUnmarshalCode + commonObjectsCode + commonPatchCode
+}
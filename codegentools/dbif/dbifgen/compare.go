@@ -0,0 +1,262 @@
+package dbifgen
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/types"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// attrDiff holds the code comparing the attributes of an object in CompareObjectsAndDiff and
+// CompareObjectDefaultAndDiff. Values are compared with != when that tells whether they are
+// equal, which is the case for basic types and for arrays and structures of these. Pointers are
+// compared by the values they point to. Slices and maps are compared element by element, so
+// that nil and empty ones are equal, by a method of the object generated for their type, and so
+// are other arrays and structures. Structures of other packages with fields the generated code
+// can not refer to are compared with != if they can be at all.
+type attrDiff struct {
+	obj *ObjectInfoJson
+	// Expression telling whether an attribute differs between obj and dbObj, by attribute name
+	differs map[string]string
+	// Methods comparing values of a type, by name, in the order they are needed
+	names   []string
+	methods map[string]*equalMethod
+	// Packages the types of the methods refer to, keyed by the name they are imported as
+	pkgs map[string]string
+}
+
+// equalMethod is a method of the object telling whether two values of typ are equal
+type equalMethod struct {
+	typ   types.Type
+	lines []string
+}
+
+// newAttrDiff returns the code comparing the attributes attrMap of the object. It fails for
+// attributes whose values can not be compared, such as interfaces and functions.
+func (obj *ObjectInfoJson) newAttrDiff(attrMap []ObjectMemberAndInfo) (*attrDiff, error) {
+	diff := &attrDiff{
+		obj:     obj,
+		differs: make(map[string]string),
+		methods: make(map[string]*equalMethod),
+		pkgs:    make(map[string]string),
+	}
+	for _, attrInfo := range attrMap {
+		attrName := attrInfo.MemberName
+		typ, err := attrInfo.memberType()
+		if err == nil {
+			diff.differs[attrName], err = diff.differ(typ, "obj."+attrName, "dbObj."+attrName)
+		}
+		if err != nil {
+			return nil, errors.New(fmt.Sprintln("Member", attrName+":", strings.TrimSpace(err.Error())))
+		}
+	}
+	return diff, nil
+}
+
+// memberType returns the type of the member. Members without a Type, of models that are not
+// loaded from their source, may only be of basic types, of named types of these and of slices
+// of both.
+func (info ObjectMembersInfo) memberType() (types.Type, error) {
+	if info.Type != nil {
+		return info.Type, nil
+	}
+	basic, ok := types.Universe.Lookup(info.baseType()).(*types.TypeName)
+	if !ok || !goBasicTypesMap[basic.Name()] {
+		return nil, errors.New(fmt.Sprintln("Type", info.VarType, "of the member is not known"))
+	}
+	typ := basic.Type()
+	if info.BaseType != "" {
+		typ = types.NewNamed(types.NewTypeName(info.Pos, nil, info.VarType, nil), typ, nil)
+	}
+	if info.IsArray {
+		typ = types.NewSlice(typ)
+	}
+	return typ, nil
+}
+
+// differ returns the expression telling whether the values a and b of type typ differ
+func (diff *attrDiff) differ(typ types.Type, a string, b string) (string, error) {
+	typ = types.Unalias(typ)
+	switch u := typ.Underlying().(type) {
+	case *types.Pointer:
+		elem, err := diff.differ(u.Elem(), "*"+a, "*"+b)
+		if err != nil {
+			return "", err
+		}
+		return "((" + a + " == nil) != (" + b + " == nil) || " + a + " != nil && " + elem + ")", nil
+	case *types.Basic:
+		if u.Kind() != types.UnsafePointer {
+			return a + " != " + b, nil
+		}
+	case *types.Array, *types.Struct, *types.Slice, *types.Map:
+		if comparedByValue(typ) {
+			return a + " != " + b, nil
+		}
+		if str, ok := u.(*types.Struct); ok && !diff.fieldsVisible(str) {
+			// The fields of a structure of another package can not all be compared one by one
+			if types.Comparable(typ) {
+				return a + " != " + b, nil
+			}
+			break
+		}
+		name, err := diff.method(typ)
+		if err != nil {
+			return "", err
+		}
+		return "!obj." + name + "(" + a + ", " + b + ")", nil
+	}
+	return "", errors.New(fmt.Sprintln("Values of type", typ, "can not be compared"))
+}
+
+// comparedByValue reports whether values of typ are equal exactly when == says so, which is the
+// case for basic types and arrays and structures of these
+func comparedByValue(typ types.Type) bool {
+	switch u := typ.Underlying().(type) {
+	case *types.Basic:
+		return u.Kind() != types.UnsafePointer
+	case *types.Array:
+		return comparedByValue(u.Elem())
+	case *types.Struct:
+		for i := 0; i < u.NumFields(); i++ {
+			if !comparedByValue(u.Field(i).Type()) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// fieldsVisible reports whether the generated code can refer to all the fields of str
+func (diff *attrDiff) fieldsVisible(str *types.Struct) bool {
+	for i := 0; i < str.NumFields(); i++ {
+		field := str.Field(i)
+		if !field.Exported() && field.Pkg() != nil && field.Pkg().Path() != diff.obj.PkgPath {
+			return false
+		}
+	}
+	return true
+}
+
+// method returns the name of the method comparing values of typ, adding it if it is not there
+// yet. The method is registered before its body is written, so that types referring to
+// themselves, as in type Node struct { Children []Node }, end up calling it.
+func (diff *attrDiff) method(typ types.Type) (string, error) {
+	base := "equal" + diff.typeName(typ)
+	name := base
+	for n := 2; ; n++ {
+		m, exist := diff.methods[name]
+		if !exist {
+			break
+		}
+		if types.Identical(m.typ, typ) {
+			return name, nil
+		}
+		name = base + strconv.Itoa(n)
+	}
+	m := &equalMethod{typ: typ}
+	diff.methods[name] = m
+	diff.names = append(diff.names, name)
+
+	typeStr := types.TypeString(typ, diff.qualifier)
+	m.lines = append(m.lines, "\nfunc (obj "+diff.obj.ObjName+") "+name+"(a, b "+typeStr+") bool {\n")
+	switch u := typ.Underlying().(type) {
+	case *types.Slice, *types.Array:
+		var elemType types.Type
+		if slice, ok := u.(*types.Slice); ok {
+			elemType = slice.Elem()
+			m.lines = append(m.lines, "if len(a) != len(b) {\nreturn false\n}\n")
+		} else {
+			elemType = u.(*types.Array).Elem()
+		}
+		elem, err := diff.differ(elemType, "a[i]", "b[i]")
+		if err != nil {
+			return "", err
+		}
+		m.lines = append(m.lines, "for i := range a {\n", "if "+elem+" {\nreturn false\n}\n", "}\n")
+	case *types.Map:
+		elem, err := diff.differ(u.Elem(), "value", "other")
+		if err != nil {
+			return "", err
+		}
+		m.lines = append(m.lines, "if len(a) != len(b) {\nreturn false\n}\n",
+			"for key, value := range a {\n",
+			"other, ok := b[key]\n",
+			"if !ok || "+elem+" {\nreturn false\n}\n",
+			"}\n")
+	case *types.Struct:
+		for i := 0; i < u.NumFields(); i++ {
+			field := u.Field(i)
+			if field.Name() == "_" {
+				continue
+			}
+			differs, err := diff.differ(field.Type(), "a."+field.Name(), "b."+field.Name())
+			if err != nil {
+				return "", err
+			}
+			m.lines = append(m.lines, "if "+differs+" {\nreturn false\n}\n")
+		}
+	}
+	m.lines = append(m.lines, "return true\n}\n")
+	return name, nil
+}
+
+// typeName returns the part of the name of the method comparing values of typ that stands for
+// the type, such as SliceOfPortMember for []PortMember
+func (diff *attrDiff) typeName(typ types.Type) string {
+	switch t := types.Unalias(typ).(type) {
+	case *types.Named:
+		name := t.Obj().Name()
+		if pkg := t.Obj().Pkg(); pkg != nil && pkg.Path() != diff.obj.PkgPath {
+			name = upperFirst(pkg.Name()) + name
+		}
+		// Types of members without a Type carry their package in their name, see memberType
+		return strings.Replace(name, ".", "", -1)
+	case *types.Basic:
+		return upperFirst(t.Name())
+	case *types.Pointer:
+		return "PtrTo" + diff.typeName(t.Elem())
+	case *types.Slice:
+		return "SliceOf" + diff.typeName(t.Elem())
+	case *types.Array:
+		return "Array" + strconv.FormatInt(t.Len(), 10) + "Of" + diff.typeName(t.Elem())
+	case *types.Map:
+		return "MapOf" + diff.typeName(t.Key()) + "To" + diff.typeName(t.Elem())
+	}
+	return "Struct"
+}
+
+func upperFirst(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	return string(unicode.ToUpper(r)) + s[size:]
+}
+
+// qualifier is the types.Qualifier of the types written in the generated code. The types of the
+// package of the object are not qualified, those of other packages are and their package is
+// imported.
+func (diff *attrDiff) qualifier(pkg *types.Package) string {
+	if pkg.Path() == diff.obj.PkgPath {
+		return ""
+	}
+	diff.pkgs[pkg.Name()] = pkg.Path()
+	return pkg.Name()
+}
+
+// attrLines returns the code adding the attribute attrName to attrs when it differs between obj
+// and dbObj
+func (diff *attrDiff) attrLines(attrName string) []string {
+	return []string{"if " + diff.differs[attrName] + " {\n", "attrs.Add(" + diff.obj.attrIdName(attrName) + ")\n", "}\n"}
+}
+
+// writeEqualFcns writes the methods comparing the values of the attributes
+func (diff *attrDiff) writeEqualFcns(buf *bytes.Buffer) {
+	for _, name := range diff.names {
+		for _, line := range diff.methods[name].lines {
+			buf.WriteString(line)
+		}
+	}
+}
@@ -1,13 +1,13 @@
-package main
+package dbifgen
 
 import (
-	"fmt"
-	"go/ast"
-	"os"
+	"bytes"
+	"sort"
+	"strconv"
 	"strings"
 )
 
-var fileHeader = `package objects
+var fileHeader = `
 import (
    "fmt"
    "encoding/json"
@@ -18,7 +18,7 @@ import (
    "strings"
    "utils/alphaNumSort"
 `
-var fileHeaderForState = `package objects
+var fileHeaderForState = `
 import (
    "fmt"
    "github.com/garyburd/redigo/redis"
@@ -66,7 +66,7 @@ var goTypeToRedisTypeMap = map[string]string{
 	"float64": "Float64",
 }
 
-func (obj *ObjectInfoJson) WriteStoreObjectInDBFcn(str *ast.StructType, fd *os.File, attrMap []ObjectMemberAndInfo, objMap map[string]ObjectInfoJson) {
+func (obj *ObjectInfoJson) WriteStoreObjectInDBFcn(buf *bytes.Buffer, attrMap []ObjectMemberAndInfo, objMap map[string]ObjectInfoJson) {
 	var lines []string
 	lines = append(lines, "\nfunc (obj "+obj.ObjName+") StoreObjectInDb(dbHdl redis.Conn) error {\n")
 	lines = append(lines,
@@ -75,7 +75,7 @@ func (obj *ObjectInfoJson) WriteStoreObjectInDBFcn(str *ast.StructType, fd *os.F
 			return errors.New(fmt.Sprintln("Failed to store object in DB", obj, err))
 		}`)
 	// Write Secondary table lines
-	secondaryLines := obj.WriteSecondaryTableInsertIntoDBFcn(str, fd, attrMap, objMap)
+	secondaryLines := obj.WriteSecondaryTableInsertIntoDBFcn(attrMap, objMap)
 	if len(secondaryLines) > 0 {
 		lines = append(lines, secondaryLines...)
 	}
@@ -88,28 +88,31 @@ func (obj *ObjectInfoJson) WriteStoreObjectInDBFcn(str *ast.StructType, fd *os.F
 			return errors.New(fmt.Sprintln("Failed to store object default in DB", obj, err))
 		}`)
 		// Write Secondary table lines
-		secondaryLines := obj.WriteSecondaryTableInsertIntoDBFcn(str, fd, attrMap, objMap)
+		secondaryLines := obj.WriteSecondaryTableInsertIntoDBFcn(attrMap, objMap)
 		if len(secondaryLines) > 0 {
 			lines = append(lines, secondaryLines...)
 		}
 		lines = append(lines, "\nreturn nil\n}")
 	}
 	for _, line := range lines {
-		fd.WriteString(line)
+		buf.WriteString(line)
 	}
-	fd.Sync()
 }
 
-func (obj *ObjectInfoJson) WriteSecondaryTableInsertIntoDBFcn(str *ast.StructType, fd *os.File, attrMap []ObjectMemberAndInfo, objMap map[string]ObjectInfoJson) []string {
+func (obj *ObjectInfoJson) WriteSecondaryTableInsertIntoDBFcn(attrMap []ObjectMemberAndInfo, objMap map[string]ObjectInfoJson) []string {
 	var lines []string
 	if strings.HasPrefix(obj.ObjName, "Vxlan") { // Temporary hack. Need to fix it. Hari. TODO
 		return lines
 	}
+	firstJson := true
 	for _, attrInfo := range attrMap {
-		if attrInfo.IsArray == true {
-			if _, ok := goBasicTypesMap[attrInfo.VarType]; !ok {
-				lines = append(lines, `
-					bytes, err := json.Marshal(obj.`+attrInfo.MemberName+`)
+		if attrInfo.storedAsJson() {
+			if firstJson {
+				lines = append(lines, "\nvar bytes []byte\n")
+				firstJson = false
+			}
+			lines = append(lines, `
+					bytes, err = json.Marshal(obj.`+attrInfo.MemberName+`)
 					if err != nil {
 						return errors.New(fmt.Sprintln("Failed to marshal struct when storing object in DB", obj, err))
 					}
@@ -117,22 +120,21 @@ func (obj *ObjectInfoJson) WriteSecondaryTableInsertIntoDBFcn(str *ast.StructTyp
 					if err != nil {
 						return errors.New(fmt.Sprintln("Failed to store object in DB", obj, err))
 					}`)
-			} else {
-				//Member is a slice of native data type elements
-				lines = append(lines, `
+		} else if attrInfo.IsArray {
+			//Member is a slice of native data type elements
+			lines = append(lines, `
 					for idx := len(obj.`+attrInfo.MemberName+`) - 1; idx >= 0; idx-- {
 						_, err := dbHdl.Do("LPUSH", obj.GetKey()+"`+attrInfo.MemberName+`", obj.`+attrInfo.MemberName+`[idx])
 						if err != nil {
 							return errors.New(fmt.Sprintln("Failed to store slice member in DB", obj, err))
 						}
 					}`)
-			}
 		}
 	}
 	return lines
 }
 
-func (obj *ObjectInfoJson) WriteDeleteObjectFromDbFcn(str *ast.StructType, fd *os.File, attrMap []ObjectMemberAndInfo, objMap map[string]ObjectInfoJson) {
+func (obj *ObjectInfoJson) WriteDeleteObjectFromDbFcn(buf *bytes.Buffer, attrMap []ObjectMemberAndInfo, objMap map[string]ObjectInfoJson) {
 	var lines []string
 	lines = append(lines, "\nfunc (obj "+obj.ObjName+") DeleteObjectFromDb(dbHdl redis.Conn) error {\n")
 	//Delete primary key
@@ -143,7 +145,7 @@ func (obj *ObjectInfoJson) WriteDeleteObjectFromDbFcn(str *ast.StructType, fd *o
 		}`)
 	//Delete key corresponding to secondary entries if any
 	for _, attrInfo := range attrMap {
-		if attrInfo.IsArray == true {
+		if attrInfo.IsArray || attrInfo.storedAsJson() {
 			lines = append(lines, `
 				_, err = dbHdl.Do("DEL", obj.GetKey()+"`+attrInfo.MemberName+`")
 				if err != nil {
@@ -155,12 +157,11 @@ func (obj *ObjectInfoJson) WriteDeleteObjectFromDbFcn(str *ast.StructType, fd *o
 		return nil 
 	}`)
 	for _, line := range lines {
-		fd.WriteString(line)
+		buf.WriteString(line)
 	}
-	fd.Sync()
 }
 
-func (obj *ObjectInfoJson) WriteGetObjectFromDbFcn(str *ast.StructType, fd *os.File, attrMap []ObjectMemberAndInfo, objMap map[string]ObjectInfoJson) {
+func (obj *ObjectInfoJson) WriteGetObjectFromDbFcn(buf *bytes.Buffer, attrMap []ObjectMemberAndInfo, objMap map[string]ObjectInfoJson) {
 	var lines []string
 	var firstListOfStructs, firstList bool = true, true
 	lines = append(lines, "\nfunc (obj "+obj.ObjName+") GetObjectFromDb(objKey string, dbHdl redis.Conn) (ConfigObj, error) {\n")
@@ -178,14 +179,13 @@ func (obj *ObjectInfoJson) WriteGetObjectFromDbFcn(str *ast.StructType, fd *os.F
 			return object, err
 		}`)*/
 	for _, attrInfo := range attrMap {
-		if attrInfo.IsArray == true {
-			if _, ok := goBasicTypesMap[attrInfo.VarType]; !ok {
-				if firstListOfStructs {
-					lines = append(lines, "\nvar strVal string\n")
-					firstListOfStructs = false
-				}
-				//Member is a slice of structs
-				lines = append(lines, `
+		if attrInfo.storedAsJson() {
+			if firstListOfStructs {
+				lines = append(lines, "\nvar strVal string\n")
+				firstListOfStructs = false
+			}
+			//Member is a slice of structs or of a nested type
+			lines = append(lines, `
 				    strVal, err = redis.String(dbHdl.Do("GET", objKey+"`+attrInfo.MemberName+`"))
 					if err != nil {
 						return object, errors.New(fmt.Sprintln("Failed to get obj from DB data", obj, err))
@@ -194,13 +194,13 @@ func (obj *ObjectInfoJson) WriteGetObjectFromDbFcn(str *ast.StructType, fd *os.F
 					if err != nil {
 						return object, errors.New(fmt.Sprintln("Failed to unmarshal db object", obj, err))
 					}`)
-			} else {
-				if firstList {
-					lines = append(lines, "\nvar idx, listLen int\n")
-					firstList = false
-				}
-				//Member is a slice of native data type elements
-				lines = append(lines, `
+		} else if attrInfo.IsArray {
+			if firstList {
+				lines = append(lines, "\nvar idx, listLen int\n")
+				firstList = false
+			}
+			//Member is a slice of native data type elements
+			lines = append(lines, `
 				    listLen, err = redis.Int(dbHdl.Do("LLEN", objKey+"`+attrInfo.MemberName+`"))
 					if err != nil {
 						return object, errors.New(fmt.Sprintln("Failed to retrieve list len for secondary table", obj, err))
@@ -216,14 +216,12 @@ func (obj *ObjectInfoJson) WriteGetObjectFromDbFcn(str *ast.StructType, fd *os.F
 							object.`+attrInfo.MemberName+` = append(object.`+attrInfo.MemberName+`, `+attrInfo.VarType+`(val))
 						}
 					}`)
-			}
 		}
 	}
 	lines = append(lines, "\nreturn object, nil\n}")
 	for _, line := range lines {
-		fd.WriteString(line)
+		buf.WriteString(line)
 	}
-	fd.Sync()
 }
 
 func (obj *ObjectInfoJson) IsNumericType(typeVal string) bool {
@@ -233,69 +231,55 @@ func (obj *ObjectInfoJson) IsNumericType(typeVal string) bool {
 	default:
 		return false
 	}
-	return false
 }
 
-func (obj *ObjectInfoJson) WriteKeyRelatedFcns(str *ast.StructType, fd *os.File, attrMap []ObjectMemberAndInfo, objMap map[string]ObjectInfoJson) {
+func (obj *ObjectInfoJson) WriteKeyRelatedFcns(buf *bytes.Buffer, attrMap []ObjectMemberAndInfo, objMap map[string]ObjectInfoJson) {
 	var lines []string
 	lines = append(lines, "\nfunc (obj "+obj.ObjName+") GetKey() string {\n")
 	numKeys := 0
 	keyStr := `key := "` + obj.ObjName + `#"`
-	for _, fld := range str.Fields.List {
-		if fld.Names != nil {
-			switch fld.Type.(type) {
-			case *ast.Ident:
-				varName := fld.Names[0].String()
-				if fld.Tag != nil {
-					if strings.Contains(fld.Tag.Value, "SNAPROUTE") {
-						idntType := fld.Type.(*ast.Ident)
-						varType := idntType.String()
-						if numKeys == 0 {
-							if obj.IsNumericType(varType) {
-								keyStr = keyStr + "+ fmt.Sprintf(\"%d\", obj." + varName + ")"
-							} else {
-								keyStr = keyStr + "+ obj." + varName
-							}
-						} else {
-							if obj.IsNumericType(varType) {
-								keyStr = keyStr + "+ \"#\" + fmt.Sprintf(\"%d\", obj." + varName + ")"
-							} else {
-								keyStr = keyStr + "+ \"#\" + obj." + varName
-							}
-						}
-						numKeys += 1
-					}
+	for _, attrInfo := range attrMap {
+		if attrInfo.IsKey && !attrInfo.IsArray {
+			varName := "obj." + attrInfo.MemberName
+			varType := attrInfo.baseType()
+			if attrInfo.BaseType != "" && varType == "string" {
+				// Keys of a named string type have to be converted to be added to the key
+				varName = "string(" + varName + ")"
+			}
+			if numKeys == 0 {
+				if obj.IsNumericType(varType) {
+					keyStr = keyStr + "+ fmt.Sprintf(\"%d\", " + varName + ")"
+				} else {
+					keyStr = keyStr + "+ " + varName
+				}
+			} else {
+				if obj.IsNumericType(varType) {
+					keyStr = keyStr + "+ \"#\" + fmt.Sprintf(\"%d\", " + varName + ")"
+				} else {
+					keyStr = keyStr + "+ \"#\" + " + varName
 				}
 			}
+			numKeys += 1
 		}
 	}
 	lines = append(lines, keyStr, `
 		return key
 		}`)
 	for _, line := range lines {
-		fd.WriteString(line)
+		buf.WriteString(line)
 	}
-	fd.Sync()
 
 }
 
-func (obj *ObjectInfoJson) WriteMergeDbObjKeysFcn(str *ast.StructType, fd *os.File, attrMap []ObjectMemberAndInfo, objMap map[string]ObjectInfoJson) {
+func (obj *ObjectInfoJson) WriteMergeDbObjKeysFcn(buf *bytes.Buffer, attrMap []ObjectMemberAndInfo, objMap map[string]ObjectInfoJson) {
 	var lines []string
 	var keyLines []string
 	configObjName := strings.TrimSuffix(obj.ObjName, "State")
 	configObj, exist := objMap[configObjName]
 	if exist && strings.Contains(configObj.Access, "w") {
-		for _, fld := range str.Fields.List {
-			if fld.Names != nil {
-				switch fld.Type.(type) {
-				case *ast.Ident:
-					varName := fld.Names[0].String()
-					if fld.Tag != nil {
-						if strings.Contains(fld.Tag.Value, "SNAPROUTE") {
-							keyLines = append(keyLines, "mergedObject."+varName+" = data."+varName+"\n")
-						}
-					}
-				}
+		for _, attrInfo := range attrMap {
+			if attrInfo.IsKey && !attrInfo.IsArray {
+				keyLines = append(keyLines, "mergedObject."+attrInfo.MemberName+" = data."+attrInfo.MemberName+"\n")
 			}
 		}
 		lines = append(lines, "\nfunc (obj "+obj.ObjName+") MergeDbObjKeys(dbObj ConfigObj) (ConfigObj, error) { \n")
@@ -307,13 +291,12 @@ func (obj *ObjectInfoJson) WriteMergeDbObjKeysFcn(str *ast.StructType, fd *os.Fi
 		lines = append(lines, "return mergedObject, nil\n")
 		lines = append(lines, "}\n")
 		for _, line := range lines {
-			fd.WriteString(line)
+			buf.WriteString(line)
 		}
-		fd.Sync()
 	}
 }
 
-func (obj *ObjectInfoJson) WriteGetAllObjFromDbFcn(str *ast.StructType, fd *os.File, attrMap []ObjectMemberAndInfo, objMap map[string]ObjectInfoJson) {
+func (obj *ObjectInfoJson) WriteGetAllObjFromDbFcn(buf *bytes.Buffer, attrMap []ObjectMemberAndInfo, objMap map[string]ObjectInfoJson) {
 	var lines []string
 	lines = append(lines, "\nfunc (obj "+obj.ObjName+") GetAllObjFromDb(dbHdl redis.Conn) (objList []ConfigObj, err error) { \n")
 	lines = append(lines,
@@ -342,14 +325,13 @@ func (obj *ObjectInfoJson) WriteGetAllObjFromDbFcn(str *ast.StructType, fd *os.F
 		return objList, nil
 	}`)
 	for _, line := range lines {
-		fd.WriteString(line)
+		buf.WriteString(line)
 	}
-	fd.Sync()
 }
 
 //FIXME: GetBulk for secondary table will be implemented as part of actual GetBulk implementation
 /*
-func (obj *ObjectInfoJson) WriteGetBulkSecondaryTableFromDBFcn(str *ast.StructType, fd *os.File, attrMap []ObjectMemberAndInfo, objMap map[string]ObjectInfoJson) []string {
+func (obj *ObjectInfoJson) WriteGetBulkSecondaryTableFromDBFcn(buf *bytes.Buffer, attrMap []ObjectMemberAndInfo, objMap map[string]ObjectInfoJson) []string {
 	var lines []string
 	//if !strings.Contains(obj.ObjName, "Policy") { // Temporary hack. Need to fix it. Hari. TODO
 	if strings.HasPrefix(obj.ObjName, "Vxlan") { // Temporary hack. Need to fix it. Hari. TODO
@@ -415,8 +397,8 @@ func (obj *ObjectInfoJson) WriteGetBulkSecondaryTableFromDBFcn(str *ast.StructTy
 }
 */
 
-//FIXME: GetBulk is currently implemented to call GetAllObj
-func (obj *ObjectInfoJson) WriteGetBulkObjFromDbFcn(str *ast.StructType, fd *os.File, attrMap []ObjectMemberAndInfo, objMap map[string]ObjectInfoJson) {
+// FIXME: GetBulk is currently implemented to call GetAllObj
+func (obj *ObjectInfoJson) WriteGetBulkObjFromDbFcn(buf *bytes.Buffer, attrMap []ObjectMemberAndInfo, objMap map[string]ObjectInfoJson) {
 	var lines []string
 	lines = append(lines, "\nfunc (obj "+obj.ObjName+") GetBulkObjFromDb(startIndex int64, count int64, dbHdl redis.Conn) (err error, objCount int64, nextMarker int64, moreExist bool, objList []ConfigObj) { \n")
 	/*lines = append(lines,
@@ -470,188 +452,54 @@ func (obj *ObjectInfoJson) WriteGetBulkObjFromDbFcn(str *ast.StructType, fd *os.
 	         return nil, int64(len(objList)), int64(cursor), moreExist, objList
     }`)
 	for _, line := range lines {
-		fd.WriteString(line)
+		buf.WriteString(line)
 	}
-	fd.Sync()
 }
 
-func (obj *ObjectInfoJson) WriteCompareObjectsAndDiffFcn(str *ast.StructType, fd *os.File, attrMap []ObjectMemberAndInfo, objMap map[string]ObjectInfoJson) {
+func (obj *ObjectInfoJson) WriteCompareObjectsAndDiffFcn(buf *bytes.Buffer, attrMap []ObjectMemberAndInfo, objMap map[string]ObjectInfoJson) {
 	var lines []string
-	lines = append(lines, "\nfunc (obj "+obj.ObjName+") CompareObjectsAndDiff(updateKeys map[string]bool, inObj ConfigObj) ([]bool, error) {\n")
-	lines = append(lines, "dbObj := inObj.("+obj.ObjName+")")
-	lines = append(lines, `
-			objTyp := reflect.TypeOf(obj)
-			objVal := reflect.ValueOf(obj)
-			dbObjVal := reflect.ValueOf(dbObj)
-			attrIds := make([]bool, objTyp.NumField())
-			idx := 0
-			for i := 0; i < objTyp.NumField(); i++ {
-				fieldTyp := objTyp.Field(i)
-				if fieldTyp.Anonymous {
-					continue
-				}
-
-				objVal := objVal.Field(i)
-				dbObjVal := dbObjVal.Field(i)
-				if _, ok := updateKeys[fieldTyp.Name]; ok {
-					if objVal.Kind() == reflect.Int {
-						if int(objVal.Int()) != int(dbObjVal.Int()) {
-							attrIds[idx] = true
-						}
-					} else if objVal.Kind() == reflect.Int8 {
-						if int8(objVal.Int()) != int8(dbObjVal.Int()) {
-							attrIds[idx] = true
-						}
-					} else if objVal.Kind() == reflect.Int16 {
-						if int16(objVal.Int()) != int16(dbObjVal.Int()) {
-							attrIds[idx] = true
-						}
-					} else if objVal.Kind() == reflect.Int32 {
-						if int32(objVal.Int()) != int32(dbObjVal.Int()) {
-							attrIds[idx] = true
-						}
-					} else if objVal.Kind() == reflect.Int64 {
-						if int64(objVal.Int()) != int64(dbObjVal.Int()) {
-							attrIds[idx] = true
-						}
-					} else if objVal.Kind() == reflect.Uint {
-						if uint(objVal.Uint()) != uint(dbObjVal.Uint()) {
-							attrIds[idx] = true
-						}
-					} else if objVal.Kind() == reflect.Uint8 {
-						if uint8(objVal.Uint()) != uint8(dbObjVal.Uint()) {
-							attrIds[idx] = true
-						}
-					} else if objVal.Kind() == reflect.Uint16 {
-						if uint16(objVal.Uint()) != uint16(dbObjVal.Uint()) {
-							attrIds[idx] = true
-						}
-					} else if objVal.Kind() == reflect.Uint32 {
-						if uint16(objVal.Uint()) != uint16(dbObjVal.Uint()) {
-							attrIds[idx] = true
-						}
-					} else if objVal.Kind() == reflect.Uint64 {
-						if uint16(objVal.Uint()) != uint16(dbObjVal.Uint()) {
-							attrIds[idx] = true
-						}
-					} else if objVal.Kind() == reflect.Float64{
-						if objVal.Float() != dbObjVal.Float() {
-							attrIds[idx] = true
-						}
-					} else if objVal.Kind() == reflect.Bool {
-						if bool(objVal.Bool()) != bool(dbObjVal.Bool()) {
-							attrIds[idx] = true
-						}
-					} else if objVal.Kind() == reflect.Slice {
-						attrIds[idx] = true
-					} else {
-						if objVal.String() != dbObjVal.String() {
-							attrIds[idx] = true
-						}
-					}
-				}
-				idx++
-
-			}
-			return attrIds[:idx], nil
-		}
-
-		`)
+	// Attributes that can not be compared have been reported by WriteDBFunctions
+	diff, _ := obj.newAttrDiff(attrMap)
+	lines = append(lines, "\nfunc (obj "+obj.ObjName+") CompareObjectsAndDiffAttrs(updateKeys map[string]bool, inObj ConfigObj) (AttrSet, error) {\n")
+	lines = append(lines, "dbObj := inObj.("+obj.ObjName+")\n")
+	lines = append(lines, "attrs := obj.NewAttrSet()\n")
+	for _, attrInfo := range attrMap {
+		lines = append(lines, "if _, ok := updateKeys[\""+attrInfo.MemberName+"\"]; ok {\n")
+		lines = append(lines, diff.attrLines(attrInfo.MemberName)...)
+		lines = append(lines, "}\n")
+	}
+	lines = append(lines, "return attrs, nil\n}\n")
+	lines = append(lines, "\nfunc (obj "+obj.ObjName+") CompareObjectsAndDiff(updateKeys map[string]bool, inObj ConfigObj) ([]bool, error) {\n",
+		"attrs, err := obj.CompareObjectsAndDiffAttrs(updateKeys, inObj)\n",
+		"return attrs.Bools(), err\n}\n")
 	for _, line := range lines {
-		fd.WriteString(line)
+		buf.WriteString(line)
 	}
-	fd.Sync()
+	diff.writeEqualFcns(buf)
 }
 
-func (obj *ObjectInfoJson) WriteCompareObjectDefaultAndDiffFcn(str *ast.StructType, fd *os.File, attrMap []ObjectMemberAndInfo, objMap map[string]ObjectInfoJson) {
+func (obj *ObjectInfoJson) WriteCompareObjectDefaultAndDiffFcn(buf *bytes.Buffer, attrMap []ObjectMemberAndInfo, objMap map[string]ObjectInfoJson) {
 	var lines []string
 	if !obj.AutoCreate && !obj.AutoDiscover {
 		return
 	}
-	lines = append(lines, "\nfunc (obj "+obj.ObjName+") CompareObjectDefaultAndDiff(inObj ConfigObj) ([]bool, error) {\n")
-	lines = append(lines, "dbObj := inObj.("+obj.ObjName+")")
-	lines = append(lines, `
-			objTyp := reflect.TypeOf(obj)
-			objVal := reflect.ValueOf(obj)
-			dbObjVal := reflect.ValueOf(dbObj)
-			attrIds := make([]bool, objTyp.NumField())
-			idx := 0
-			for i := 0; i < objTyp.NumField(); i++ {
-				fieldTyp := objTyp.Field(i)
-				if fieldTyp.Anonymous {
-					continue
-				}
-
-				objVal := objVal.Field(i)
-				dbObjVal := dbObjVal.Field(i)
-				if objVal.Kind() == reflect.Int {
-					if int(objVal.Int()) != int(dbObjVal.Int()) {
-						attrIds[idx] = true
-					}
-				} else if objVal.Kind() == reflect.Int8 {
-					if int8(objVal.Int()) != int8(dbObjVal.Int()) {
-						attrIds[idx] = true
-					}
-				} else if objVal.Kind() == reflect.Int16 {
-					if int16(objVal.Int()) != int16(dbObjVal.Int()) {
-						attrIds[idx] = true
-					}
-				} else if objVal.Kind() == reflect.Int32 {
-					if int32(objVal.Int()) != int32(dbObjVal.Int()) {
-						attrIds[idx] = true
-					}
-				} else if objVal.Kind() == reflect.Int64 {
-					if int64(objVal.Int()) != int64(dbObjVal.Int()) {
-						attrIds[idx] = true
-					}
-				} else if objVal.Kind() == reflect.Uint {
-					if uint(objVal.Uint()) != uint(dbObjVal.Uint()) {
-						attrIds[idx] = true
-					}
-				} else if objVal.Kind() == reflect.Uint8 {
-					if uint8(objVal.Uint()) != uint8(dbObjVal.Uint()) {
-						attrIds[idx] = true
-					}
-				} else if objVal.Kind() == reflect.Uint16 {
-					if uint16(objVal.Uint()) != uint16(dbObjVal.Uint()) {
-						attrIds[idx] = true
-					}
-				} else if objVal.Kind() == reflect.Uint32 {
-					if uint16(objVal.Uint()) != uint16(dbObjVal.Uint()) {
-						attrIds[idx] = true
-					}
-				} else if objVal.Kind() == reflect.Uint64 {
-					if uint16(objVal.Uint()) != uint16(dbObjVal.Uint()) {
-						attrIds[idx] = true
-					}
-				} else if objVal.Kind() == reflect.Float64{
-					if objVal.Float() != dbObjVal.Float() {
-						attrIds[idx] = true
-					}
-				} else if objVal.Kind() == reflect.Bool {
-					if bool(objVal.Bool()) != bool(dbObjVal.Bool()) {
-						attrIds[idx] = true
-					}
-				} else if objVal.Kind() == reflect.Slice {
-					attrIds[idx] = true
-				} else {
-					if objVal.String() != dbObjVal.String() {
-						attrIds[idx] = true
-					}
-				}
-				idx++
-			}
-			return attrIds[:idx], nil
-		}
-
-		`)
+	diff, _ := obj.newAttrDiff(attrMap)
+	lines = append(lines, "\nfunc (obj "+obj.ObjName+") CompareObjectDefaultAndDiffAttrs(inObj ConfigObj) (AttrSet, error) {\n")
+	lines = append(lines, "dbObj := inObj.("+obj.ObjName+")\n")
+	lines = append(lines, "attrs := obj.NewAttrSet()\n")
+	for _, attrInfo := range attrMap {
+		lines = append(lines, diff.attrLines(attrInfo.MemberName)...)
+	}
+	lines = append(lines, "return attrs, nil\n}\n")
+	lines = append(lines, "\nfunc (obj "+obj.ObjName+") CompareObjectDefaultAndDiff(inObj ConfigObj) ([]bool, error) {\n",
+		"attrs, err := obj.CompareObjectDefaultAndDiffAttrs(inObj)\n",
+		"return attrs.Bools(), err\n}\n")
 	for _, line := range lines {
-		fd.WriteString(line)
+		buf.WriteString(line)
 	}
-	fd.Sync()
 }
 
-func (obj *ObjectInfoJson) WriteUpdateObjectInDbFcn(str *ast.StructType, fd *os.File, attrMap []ObjectMemberAndInfo, objMap map[string]ObjectInfoJson) {
+func (obj *ObjectInfoJson) WriteUpdateObjectInDbFcn(buf *bytes.Buffer, attrMap []ObjectMemberAndInfo, objMap map[string]ObjectInfoJson) {
 	var lines []string
 	lines = append(lines, "\nfunc (obj "+obj.ObjName+") UpdateObjectInDb(inObj ConfigObj, attrSet []bool, dbHdl redis.Conn) error {\n")
 	lines = append(lines,
@@ -662,16 +510,32 @@ func (obj *ObjectInfoJson) WriteUpdateObjectInDbFcn(str *ast.StructType, fd *os.
 	lines = append(lines, `
 						objTyp := reflect.TypeOf(obj)
 						objVal := reflect.ValueOf(obj)
-						idx := 0
-						for i := 0; i < objTyp.NumField(); i++ {
-							if fieldTyp := objTyp.Field(i); fieldTyp.Anonymous {
-								continue
-							}
+						for idx, i := range attrFields(objTyp) {
 							if attrSet[idx] {
-								fieldTyp := objTyp.Field(i)
-								fieldVal := objVal.Field(i)
+								fieldTyp := objTyp.FieldByIndex(i)
+								fieldVal := objVal.FieldByIndex(i)
 								fieldName := fieldTyp.Name
-								if fieldVal.Kind() == reflect.Slice {
+								`)
+	// Members kept as json text are stored the same way whatever their kind
+	var jsonConds []string
+	for _, attrInfo := range attrMap {
+		if attrInfo.storedAsJson() {
+			jsonConds = append(jsonConds, "fieldName == \""+attrInfo.MemberName+"\"")
+		}
+	}
+	if len(jsonConds) > 0 {
+		lines = append(lines, "if "+strings.Join(jsonConds, " || ")+" {\n", `
+									bytes, err := json.Marshal(fieldVal.Interface())
+									if err != nil {
+										return err
+									}
+									_, err = dbHdl.Do("SET", obj.GetKey()+fieldName, string(bytes))
+									if err != nil {
+										return err
+									}
+								} else `)
+	}
+	lines = append(lines, `if fieldVal.Kind() == reflect.Slice {
 									_, err := dbHdl.Do("DEL", obj.GetKey()+fieldName)
 									if err != nil {
 										return err
@@ -704,16 +568,14 @@ func (obj *ObjectInfoJson) WriteUpdateObjectInDbFcn(str *ast.StructType, fd *os.
 									}
 								}
 							}
-							idx++
 						}
 						return nil
 					}`)
 	for _, line := range lines {
-		fd.WriteString(line)
+		buf.WriteString(line)
 	}
-	fd.Sync()
 }
-func (obj *ObjectInfoJson) WriteCopyRecursiveFcn(str *ast.StructType, fd *os.File) {
+func (obj *ObjectInfoJson) WriteCopyRecursiveFcn(buf *bytes.Buffer) {
 	var lines []string
 	lines = append(lines, "\nfunc (obj "+obj.ObjName+")")
 	lines = append(lines, ` CopyRecursive(dest, src reflect.Value) {
@@ -727,6 +589,28 @@ func (obj *ObjectInfoJson) WriteCopyRecursiveFcn(str *ast.StructType, fd *os.Fil
 		                       for i := 0; i < src.NumField(); i++ {
                                     obj.CopyRecursive(dest.Field(i),src.Field(i))
 	                          }
+	                           case reflect.Array:
+		                       for i := 0; i < src.Len(); i++ {
+	                               obj.CopyRecursive(dest.Index(i), src.Index(i))
+	                           }
+	                           case reflect.Map:
+		                       if src.IsNil() {
+			                       dest.Set(reflect.Zero(src.Type()))
+			                       return
+		                       }
+		                       dest.Set(reflect.MakeMap(src.Type()))
+		                       for _, key := range src.MapKeys() {
+			                       val := reflect.New(src.Type().Elem()).Elem()
+			                       obj.CopyRecursive(val, src.MapIndex(key))
+			                       dest.SetMapIndex(key, val)
+		                       }
+	                           case reflect.Ptr:
+		                       if src.IsNil() {
+			                       dest.Set(reflect.Zero(src.Type()))
+			                       return
+		                       }
+		                       dest.Set(reflect.New(src.Type().Elem()))
+		                       obj.CopyRecursive(dest.Elem(), src.Elem())
 	                           case reflect.String:
 		                       dest.SetString(src.Interface().(string))
  	                           case reflect.Int:
@@ -741,123 +625,10 @@ func (obj *ObjectInfoJson) WriteCopyRecursiveFcn(str *ast.StructType, fd *os.Fil
                        }`)
 	lines = append(lines, "\n")
 	for _, line := range lines {
-		fd.WriteString(line)
-	}
-	fd.Sync()
-}
-func (obj *ObjectInfoJson) WriteMergeDbAndConfigObjForPatchUpdateFcn(str *ast.StructType, fd *os.File, attrMap []ObjectMemberAndInfo, objMap map[string]ObjectInfoJson) {
-	var lines []string
-	lines = append(lines, "\nfunc (obj "+obj.ObjName+") MergeDbAndConfigObjForPatchUpdate(dbObj ConfigObj, patchOpInfoSlice []PatchOpInfo) (ConfigObj, []bool, error) {\n")
-	lines = append(lines, "var mergedObject, tempObject  "+obj.ObjName+"\n")
-	lines = append(lines, `objTyp := reflect.TypeOf(obj)
-						dbObjVal := reflect.ValueOf(dbObj)
-						mergedObjVal := reflect.ValueOf(&mergedObject)
-	                      diff := make([]bool, objTyp.NumField())
-	                      for i := 0; i < objTyp.NumField(); i++ {
-							fieldTyp := objTyp.Field(i)
-		                      if fieldTyp.Anonymous {
-			                      continue
-		                      }
-		                      dbObjField := dbObjVal.Field(i)
-							if dbObjField.Kind() == reflect.Int ||
-								dbObjField.Kind() == reflect.Int8 ||
-								dbObjField.Kind() == reflect.Int16 ||
-								dbObjField.Kind() == reflect.Int32 ||
-								dbObjField.Kind() == reflect.Int64 {
-								mergedObjVal.Elem().Field(i).SetInt(dbObjField.Int())
-							} else if dbObjField.Kind() == reflect.Uint ||
-								dbObjField.Kind() == reflect.Uint ||
-								dbObjField.Kind() == reflect.Uint8 ||
-								dbObjField.Kind() == reflect.Uint16 ||
-								dbObjField.Kind() == reflect.Uint32 {
-								mergedObjVal.Elem().Field(i).SetUint(dbObjField.Uint())
-							} else if dbObjField.Kind() == reflect.Float64 {
-								mergedObjVal.Elem().Field(i).SetFloat(dbObjField.Float())
-							} else if dbObjField.Kind() == reflect.Bool {
-								mergedObjVal.Elem().Field(i).SetBool(dbObjField.Bool())
-							} else if dbObjField.Kind() == reflect.Slice {
-                                   obj.CopyRecursive(mergedObjVal.Elem().Field(i), dbObjField)
-                              } else {
-								mergedObjVal.Elem().Field(i).SetString(dbObjField.String())
-							}
-						}
-       	                 for _, patchOpInfo := range patchOpInfoSlice {
-		                     idx := 0
-	                         for i := 0; i < objTyp.NumField(); i++ {
-		                         fieldTyp := objTyp.Field(i)
-		                         if fieldTyp.Anonymous {
-			                        continue
-		                         }
-			                    if fieldTyp.Name == patchOpInfo.Path {
-				                   diff[idx] = true
-				                   switch patchOpInfo.Path {
-				`)
-	for _, attrInfo := range attrMap {
-		attrStr := "\"" + attrInfo.MemberName + "\""
-		lines = append(lines, "case "+attrStr+":\n")
-		lines = append(lines, "err := json.Unmarshal([]byte(patchOpInfo.Value), &tempObject."+attrInfo.MemberName+")\n")
-		lines = append(lines, `
-						                  if err != nil {
-							                 fmt.Println("error unmarshaling value:", err)
-							                 return mergedObject, diff, errors.New(fmt.Sprintln("error unmarshaling value:", err))
-						                  }
-						                  switch patchOpInfo.Op {
-						`)
-		if attrInfo.IsArray {
-			lines = append(lines, `
-						                      case "add":
-						   `)
-			lines = append(lines, " for j := 0;j< len(tempObject."+attrInfo.MemberName+");j++ {\n")
-			lines = append(lines, "mergedObject."+attrInfo.MemberName+"= append(mergedObject."+attrInfo.MemberName+", tempObject."+attrInfo.MemberName+"[j])\n")
-			lines = append(lines, "}\n")
-			lines = append(lines, `
-						                      case "remove":
-						`)
-			lines = append(lines, "for k := 0; k < len(tempObject."+attrInfo.MemberName+"); k++ {\n")
-			lines = append(lines, `
-							found := false
-							match := -1
-					`)
-			lines = append(lines, "for k2 := 0 ; k2 < len(mergedObject."+attrInfo.MemberName+");k2++{\n")
-			lines = append(lines, "if mergedObject."+attrInfo.MemberName+"[k2] == tempObject."+attrInfo.MemberName+"[k] {")
-			lines = append(lines, `
-								    found = true
-									match = k2 
-									break
-								}
-							}
-							if found {
-					`)
-			lines = append(lines, "mergedObject."+attrInfo.MemberName+"[match]  = mergedObject."+attrInfo.MemberName+"[len(mergedObject."+attrInfo.MemberName+") - 1]\n")
-			lines = append(lines, "mergedObject."+attrInfo.MemberName+" = mergedObject."+attrInfo.MemberName+"[:(len(mergedObject."+attrInfo.MemberName+") - 1)]\n")
-			lines = append(lines, `
-							}
-						}
-						 `)
-		}
-		lines = append(lines, `
-						                      case "replace":
-											default:				   
-								                 return mergedObject, diff, errors.New("Invalid patch op type ")
-				                           }
-					    `)
-	}
-	lines = append(lines, `
-	                                }
-				                    break
-			                     }
-			                     idx++
-		                      }
-	                     }
-						return mergedObject , diff, nil
-					}
-					`)
-	for _, line := range lines {
-		fd.WriteString(line)
+		buf.WriteString(line)
 	}
-	fd.Sync()
 }
-func (obj *ObjectInfoJson) WriteMergeDbAndConfigObjFcn(str *ast.StructType, fd *os.File, attrMap []ObjectMemberAndInfo, objMap map[string]ObjectInfoJson) {
+func (obj *ObjectInfoJson) WriteMergeDbAndConfigObjFcn(buf *bytes.Buffer, attrMap []ObjectMemberAndInfo, objMap map[string]ObjectInfoJson) {
 	var lines []string
 	lines = append(lines, "\nfunc (obj "+obj.ObjName+") MergeDbAndConfigObj(dbObj ConfigObj, attrSet []bool) (ConfigObj, error) {\n")
 	lines = append(lines, "var mergedObject  "+obj.ObjName+"\n")
@@ -865,35 +636,30 @@ func (obj *ObjectInfoJson) WriteMergeDbAndConfigObjFcn(str *ast.StructType, fd *
 						objVal := reflect.ValueOf(obj)
 						dbObjVal := reflect.ValueOf(dbObj)
 						mergedObjVal := reflect.ValueOf(&mergedObject)
-						idx := 0
-						for i := 0; i < objTyp.NumField(); i++ {
-							if fieldTyp := objTyp.Field(i); fieldTyp.Anonymous {
-								continue
-							}
-
-							objField := objVal.Field(i)
-							dbObjField := dbObjVal.Field(i)
+						for idx, i := range attrFields(objTyp) {
+							objField := objVal.FieldByIndex(i)
+							dbObjField := dbObjVal.FieldByIndex(i)
 							if attrSet[idx] {
 								if dbObjField.Kind() == reflect.Int ||
 									dbObjField.Kind() == reflect.Int8 ||
 									dbObjField.Kind() == reflect.Int16 ||
 									dbObjField.Kind() == reflect.Int32 ||
 									dbObjField.Kind() == reflect.Int64 {
-									mergedObjVal.Elem().Field(i).SetInt(objField.Int())
+									mergedObjVal.Elem().FieldByIndex(i).SetInt(objField.Int())
 								} else if dbObjField.Kind() == reflect.Uint ||
 									dbObjField.Kind() == reflect.Uint8 ||
 									dbObjField.Kind() == reflect.Uint16 ||
 									dbObjField.Kind() == reflect.Uint32 ||
 									dbObjField.Kind() == reflect.Uint64 {
-									mergedObjVal.Elem().Field(i).SetUint(objField.Uint())
+									mergedObjVal.Elem().FieldByIndex(i).SetUint(objField.Uint())
 							    } else if dbObjField.Kind() == reflect.Float64 {
-								    mergedObjVal.Elem().Field(i).SetFloat(objField.Float())
+								    mergedObjVal.Elem().FieldByIndex(i).SetFloat(objField.Float())
 								} else if dbObjField.Kind() == reflect.Bool {
-									mergedObjVal.Elem().Field(i).SetBool(objField.Bool())
-								} else if dbObjField.Kind() == reflect.Slice {
-                                         obj.CopyRecursive(mergedObjVal.Elem().Field(i), objField)
-                                   } else {
-									mergedObjVal.Elem().Field(i).SetString(objField.String())
+									mergedObjVal.Elem().FieldByIndex(i).SetBool(objField.Bool())
+								} else if dbObjField.Kind() == reflect.String {
+									mergedObjVal.Elem().FieldByIndex(i).SetString(objField.String())
+								} else {
+									obj.CopyRecursive(mergedObjVal.Elem().FieldByIndex(i), objField)
 								}
 							} else {
 								if dbObjField.Kind() == reflect.Int ||
@@ -901,51 +667,41 @@ func (obj *ObjectInfoJson) WriteMergeDbAndConfigObjFcn(str *ast.StructType, fd *
 									dbObjField.Kind() == reflect.Int16 ||
 									dbObjField.Kind() == reflect.Int32 ||
 									dbObjField.Kind() == reflect.Int64 {
-									mergedObjVal.Elem().Field(i).SetInt(dbObjField.Int())
+									mergedObjVal.Elem().FieldByIndex(i).SetInt(dbObjField.Int())
 								} else if dbObjField.Kind() == reflect.Uint ||
 									dbObjField.Kind() == reflect.Uint ||
 									dbObjField.Kind() == reflect.Uint8 ||
 									dbObjField.Kind() == reflect.Uint16 ||
 									dbObjField.Kind() == reflect.Uint32 {
-									mergedObjVal.Elem().Field(i).SetUint(dbObjField.Uint())
+									mergedObjVal.Elem().FieldByIndex(i).SetUint(dbObjField.Uint())
 								} else if dbObjField.Kind() == reflect.Bool {
-									mergedObjVal.Elem().Field(i).SetBool(dbObjField.Bool())
+									mergedObjVal.Elem().FieldByIndex(i).SetBool(dbObjField.Bool())
 							    } else if dbObjField.Kind() == reflect.Float64 {
-								    mergedObjVal.Elem().Field(i).SetFloat(dbObjField.Float())
-								} else if dbObjField.Kind() == reflect.Slice {
-                                     obj.CopyRecursive(mergedObjVal.Elem().Field(i), dbObjField)
-                                   } else {
-									mergedObjVal.Elem().Field(i).SetString(dbObjField.String())
+								    mergedObjVal.Elem().FieldByIndex(i).SetFloat(dbObjField.Float())
+								} else if dbObjField.Kind() == reflect.String {
+									mergedObjVal.Elem().FieldByIndex(i).SetString(dbObjField.String())
+								} else {
+									obj.CopyRecursive(mergedObjVal.Elem().FieldByIndex(i), dbObjField)
 								}
 							}
-							idx++
-
 						}
 						return mergedObject , nil
 					}
 					`)
 	for _, line := range lines {
-		fd.WriteString(line)
+		buf.WriteString(line)
 	}
-	fd.Sync()
 }
-func (obj *ObjectInfoJson) WriteSortObjListFcn(str *ast.StructType, fd *os.File, attrMap []ObjectMemberAndInfo, objMap map[string]ObjectInfoJson) {
+func (obj *ObjectInfoJson) WriteSortObjListFcn(buf *bytes.Buffer, attrMap []ObjectMemberAndInfo, objMap map[string]ObjectInfoJson) {
 	var lines []string
 	var keyVarType string
+	var keyIsNamed bool
 	key := ""
-	for _, fld := range str.Fields.List {
-		if fld.Names != nil {
-			switch fld.Type.(type) {
-			case *ast.Ident:
-				varName := fld.Names[0].String()
-				if fld.Tag != nil {
-					if strings.Contains(fld.Tag.Value, "SNAPROUTE") && key == "" {
-						key = varName
-						idntType := fld.Type.(*ast.Ident)
-						keyVarType = idntType.String()
-					}
-				}
-			}
+	for _, attrInfo := range attrMap {
+		if attrInfo.IsKey && !attrInfo.IsArray && key == "" {
+			key = attrInfo.MemberName
+			keyVarType = attrInfo.baseType()
+			keyIsNamed = attrInfo.BaseType != ""
 		}
 	}
 	if key != "" {
@@ -955,7 +711,11 @@ func (obj *ObjectInfoJson) WriteSortObjListFcn(str *ast.StructType, fd *os.File,
 		if obj.IsNumericType(keyVarType) {
 			lines = append(lines, "func (a "+obj.ObjName+"s) Less(i, j int) bool { return (a[i]."+key+" < a[j]."+key+") }\n")
 		} else {
-			lines = append(lines, "func (a "+obj.ObjName+"s) Less(i, j int) bool { return (alphaNumSort.Compare(a[i]."+key+", a[j]."+key+") == -1) }\n")
+			keyI, keyJ := "a[i]."+key, "a[j]."+key
+			if keyVarType == "string" && keyIsNamed {
+				keyI, keyJ = "string("+keyI+")", "string("+keyJ+")"
+			}
+			lines = append(lines, "func (a "+obj.ObjName+"s) Less(i, j int) bool { return (alphaNumSort.Compare("+keyI+", "+keyJ+") == -1) }\n")
 		}
 		lines = append(lines, "\nfunc (obj "+obj.ObjName+") SortObjList(objList []ConfigObj) []ConfigObj {\n")
 		lines = append(lines, "sortedObjList := make([]"+obj.ObjName+", len(objList))\n")
@@ -967,35 +727,28 @@ func (obj *ObjectInfoJson) WriteSortObjListFcn(str *ast.StructType, fd *os.File,
 		lines = append(lines, "retObjList[idx] = object\n}\n")
 		lines = append(lines, "return retObjList\n}\n")
 		for _, line := range lines {
-			fd.WriteString(line)
+			buf.WriteString(line)
 		}
-		fd.Sync()
 	}
 }
 
+// ConvertObjectMembersMapToOrderedSlice returns the members in the order they are declared in the go structure
 func (obj *ObjectInfoJson) ConvertObjectMembersMapToOrderedSlice(attrMap map[string]ObjectMembersInfo) (attrMapSlice []ObjectMemberAndInfo) {
-
-	for i := 1; i < len(attrMap)+1; i++ {
-		for attr, info := range attrMap {
-			if i == info.Position {
-				newMember := ObjectMemberAndInfo{
-					ObjectMembersInfo: ObjectMembersInfo{
-						VarType:     info.VarType,
-						IsKey:       info.IsKey,
-						IsArray:     info.IsArray,
-						Description: info.Description,
-						DefaultVal:  info.DefaultVal,
-						Position:    info.Position,
-					},
-					MemberName: attr,
-				}
-				attrMapSlice = append(attrMapSlice, newMember)
-			}
+	for attr, info := range attrMap {
+		attrMapSlice = append(attrMapSlice, ObjectMemberAndInfo{
+			ObjectMembersInfo: info,
+			MemberName:        attr,
+		})
+	}
+	sort.Slice(attrMapSlice, func(i, j int) bool {
+		if attrMapSlice[i].Position != attrMapSlice[j].Position {
+			return attrMapSlice[i].Position < attrMapSlice[j].Position
 		}
-	}
+		return attrMapSlice[i].MemberName < attrMapSlice[j].MemberName
+	})
 	return
 }
-func (obj *ObjectInfoJson) WriteLicenseInfo(fd *os.File) {
+func (obj *ObjectInfoJson) WriteLicenseInfo(buf *bytes.Buffer) {
 	var lines []string
 	lines = append(lines, `
 //Copyright [2016] [SnapRoute Inc]
@@ -1020,73 +773,85 @@ func (obj *ObjectInfoJson) WriteLicenseInfo(fd *os.File) {
 // |  |     |  ----. |  |____ /  .  \  .----)   |      \    /\    /    |  |     |  |     |   ----.|  |  |  | 
 // |__|     |_______||_______/__/ \__\ |_______/        \__/  \__/     |__|     |__|      \______||__|  |__| 
 //                                                                                                           
-	`)
+
+`)
 	for _, line := range lines {
-		fd.WriteString(line)
+		buf.WriteString(line)
 	}
-	fd.Sync()
 }
 
-func (obj *ObjectInfoJson) WriteDBFunctions(str *ast.StructType, attrMap map[string]ObjectMembersInfo, objMap map[string]ObjectInfoJson) {
+// WriteDBFunctions returns the contents of the gen_<object>dbif.go file of the object
+func (obj *ObjectInfoJson) WriteDBFunctions(packageName string, attrMap map[string]ObjectMembersInfo, objMap map[string]ObjectInfoJson) ([]byte, error) {
+	var buf bytes.Buffer
 	fileHeaderOptionalForState := ""
-	dbFile, err := os.Create(obj.DbFileName)
-	if err != nil {
-		fmt.Println("Failed to open the file", obj.DbFileName)
-		return
-	}
-	defer dbFile.Close()
-	obj.WriteLicenseInfo(dbFile)
+	obj.WriteLicenseInfo(&buf)
 	attrMapSlice := obj.ConvertObjectMembersMapToOrderedSlice(attrMap)
 	if strings.Contains(obj.Access, "w") || strings.Contains(obj.Access, "rw") {
 		fileHeaderOptionalForState = fileHeaderOptionalForState +
 			`       
 							"strconv"
 							`
-		dbFile.WriteString(fileHeader)
-		dbFile.WriteString(fileHeaderOptionalForState)
-		dbFile.WriteString(endFileHeaderState)
-		obj.WriteStoreObjectInDBFcn(str, dbFile, attrMapSlice, objMap)
-		obj.WriteDeleteObjectFromDbFcn(str, dbFile, attrMapSlice, objMap)
-		obj.WriteGetObjectFromDbFcn(str, dbFile, attrMapSlice, objMap)
-		obj.WriteKeyRelatedFcns(str, dbFile, attrMapSlice, objMap)
-		obj.WriteGetAllObjFromDbFcn(str, dbFile, attrMapSlice, objMap)
-		obj.WriteCompareObjectsAndDiffFcn(str, dbFile, attrMapSlice, objMap)
-		obj.WriteCompareObjectDefaultAndDiffFcn(str, dbFile, attrMapSlice, objMap)
-		obj.WriteUpdateObjectInDbFcn(str, dbFile, attrMapSlice, objMap)
-		obj.WriteCopyRecursiveFcn(str, dbFile)
-		obj.WriteMergeDbAndConfigObjFcn(str, dbFile, attrMapSlice, objMap)
-		obj.WriteMergeDbAndConfigObjForPatchUpdateFcn(str, dbFile, attrMapSlice, objMap)
-		obj.WriteGetBulkObjFromDbFcn(str, dbFile, attrMapSlice, objMap)
-		obj.WriteSortObjListFcn(str, dbFile, attrMapSlice, objMap)
+		// The methods comparing attributes may name types of other packages
+		diff, err := obj.newAttrDiff(attrMapSlice)
+		if err != nil {
+			return nil, err
+		}
+		for name, pkgPath := range diff.pkgs {
+			if strings.Contains(fileHeader+fileHeaderOptionalForState, strconv.Quote(pkgPath)) {
+				delete(diff.pkgs, name)
+			}
+		}
+		fileHeaderOptionalForState += strings.Join(importLines(diff.pkgs), "")
+		buf.WriteString("package " + packageName)
+		buf.WriteString(fileHeader)
+		buf.WriteString(fileHeaderOptionalForState)
+		buf.WriteString(endFileHeaderState)
+		obj.WriteStoreObjectInDBFcn(&buf, attrMapSlice, objMap)
+		obj.WriteDeleteObjectFromDbFcn(&buf, attrMapSlice, objMap)
+		obj.WriteGetObjectFromDbFcn(&buf, attrMapSlice, objMap)
+		obj.WriteKeyRelatedFcns(&buf, attrMapSlice, objMap)
+		obj.WriteGetAllObjFromDbFcn(&buf, attrMapSlice, objMap)
+		obj.WriteCompareObjectsAndDiffFcn(&buf, attrMapSlice, objMap)
+		obj.WriteCompareObjectDefaultAndDiffFcn(&buf, attrMapSlice, objMap)
+		obj.WriteUpdateObjectInDbFcn(&buf, attrMapSlice, objMap)
+		obj.WriteCopyRecursiveFcn(&buf)
+		obj.WriteMergeDbAndConfigObjFcn(&buf, attrMapSlice, objMap)
+		obj.WritePatchFcns(&buf, attrMapSlice, objMap)
+		obj.WriteGetBulkObjFromDbFcn(&buf, attrMapSlice, objMap)
+		obj.WriteSortObjListFcn(&buf, attrMapSlice, objMap)
+		obj.WriteAttrSetFcns(&buf, attrMapSlice, objMap)
+		if err := obj.WriteValidateFcn(&buf, attrMapSlice, objMap); err != nil {
+			return nil, err
+		}
 	} else {
 		if obj.UsesStateDB {
 			fileHeaderOptionalForState = fileHeaderOptionalForState +
 				`
 				"strconv"
 				`
-			for _, attrInfo := range attrMap {
-				if attrInfo.IsArray == true {
-					if _, ok := goBasicTypesMap[attrInfo.VarType]; !ok {
-						fileHeaderOptionalForState = fileHeaderOptionalForState +
-							`
+			for _, attrInfo := range attrMapSlice {
+				if attrInfo.storedAsJson() {
+					fileHeaderOptionalForState = fileHeaderOptionalForState +
+						`
 							"encoding/json"
 							`
-					}
+					break
 				}
 			}
 		}
-		dbFile.WriteString(fileHeaderForState)
-		dbFile.WriteString(fileHeaderOptionalForState)
-		dbFile.WriteString(endFileHeaderState)
-		obj.WriteKeyRelatedFcns(str, dbFile, attrMapSlice, objMap)
-		obj.WriteMergeDbObjKeysFcn(str, dbFile, attrMapSlice, objMap)
+		buf.WriteString("package " + packageName)
+		buf.WriteString(fileHeaderForState)
+		buf.WriteString(fileHeaderOptionalForState)
+		buf.WriteString(endFileHeaderState)
+		obj.WriteKeyRelatedFcns(&buf, attrMapSlice, objMap)
+		obj.WriteMergeDbObjKeysFcn(&buf, attrMapSlice, objMap)
 		if obj.UsesStateDB {
-			obj.WriteStoreObjectInDBFcn(str, dbFile, attrMapSlice, objMap)
-			obj.WriteDeleteObjectFromDbFcn(str, dbFile, attrMapSlice, objMap)
-			obj.WriteGetObjectFromDbFcn(str, dbFile, attrMapSlice, objMap)
-			obj.WriteGetAllObjFromDbFcn(str, dbFile, attrMapSlice, objMap)
-			obj.WriteGetBulkObjFromDbFcn(str, dbFile, attrMapSlice, objMap)
+			obj.WriteStoreObjectInDBFcn(&buf, attrMapSlice, objMap)
+			obj.WriteDeleteObjectFromDbFcn(&buf, attrMapSlice, objMap)
+			obj.WriteGetObjectFromDbFcn(&buf, attrMapSlice, objMap)
+			obj.WriteGetAllObjFromDbFcn(&buf, attrMapSlice, objMap)
+			obj.WriteGetBulkObjFromDbFcn(&buf, attrMapSlice, objMap)
 		}
 	}
-	dbFile.Sync()
+	return buf.Bytes(), nil
 }
@@ -0,0 +1,534 @@
+package dbifgen
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	OBJECT_CONFIG_FILE   = "genObjectConfig.json"
+	OBJECT_SRC_INFO_FILE = "goObjInfo.json"
+	ACTION_CONFIG_FILE   = "genObjectAction.json"
+	ACTION_SRC_INFO_FILE = "goActionInfo.json"
+	// Index files, the objects of genObjectConfig.json/genObjectAction.json together with the hand
+	// coded ones, with the flags and parent/child links derived from the models. The config files
+	// are only read, the tools downstream read the index files.
+	OBJECT_INDEX_FILE    = "genObjectConfigIndex.json"
+	ACTION_INDEX_FILE    = "genObjectActionIndex.json"
+	GENERATED_FILES_LIST = "generatedGoFiles.txt"
+	OBJECTS_INTERFACE    = "ConfigObj"
+	ACTIONS_INTERFACE    = "ActionObj"
+)
+
+// This structure represents the json layout for config objects
+type ObjectInfoJson struct {
+	Access        string   `json:"access"`
+	Owner         string   `json:"owner"`
+	SrcFile       string   `json:"srcfile"`
+	Multiplicity  string   `json:"multiplicity"`
+	Accelerated   bool     `json:"accelerated"`
+	UsesStateDB   bool     `json:"usesStateDB"`
+	AutoCreate    bool     `json:"autoCreate"`
+	AutoDiscover  bool     `json:"autoDiscover"`
+	LinkedObjects []string `json:"linkedObjects"`
+	Parent        string   `json:"parent"`
+	// If set, the generated unmarshal method ignores unknown attributes instead of failing
+	LenientUnmarshal bool     `json:"lenientUnmarshal,omitempty"`
+	ObjName          string   `json:"-"`
+	DbFileName       string   `json:"-"`
+	AttrList         []string `json:"-"`
+	// Position of the go structure of the object and the import path of its package
+	Pos     token.Pos `json:"-"`
+	PkgPath string    `json:"-"`
+}
+
+// This structure represents the a golang Structure for a config object
+type ObjectMembersInfo struct {
+	VarType      string   `json:"type"`
+	IsKey        bool     `json:"isKey"`
+	IsArray      bool     `json:"isArray"`
+	Description  string   `json:"description"`
+	DefaultVal   string   `json:"default"`
+	IsDefaultSet bool     `json:"isDefaultSet"`
+	Position     int      `json:"position"`
+	Selections   []string `json:"selections"`
+	QueryParam   string   `json:"queryparam"`
+	Accelerated  bool     `json:"accelerated"`
+	Min          int      `json:"min"`
+	Max          int      `json:"max"`
+	Len          int      `json:"len"`
+	UsesStateDB  bool     `json:"usesStateDB"`
+	AutoCreate   bool     `json:"autoCreate"`
+	AutoDiscover bool     `json:"autoDiscover"`
+	Parent       string   `json:"-"` //`json:"parent"`
+	IsParentSet  bool     `json:"-"` //`json:"isParentSet"`
+	Unit         string   `json:"unit"`
+	// Intervals of allowed values given by the RANGE tag
+	Ranges []ValueRange `json:"ranges,omitempty"`
+	// Type of members that are not of a named type or a slice of one, such as pointers, maps,
+	// structs, types of other packages and slices of these. VarType is its go syntax, or that of
+	// the element type for slices.
+	GoType *MemberType `json:"goType,omitempty"`
+	// Embedded structure the member is declared in, empty for members of the object itself
+	Embedded string `json:"embedded,omitempty"`
+	// Basic type underlying VarType when that is a named type, such as uint16 for VlanId
+	BaseType string `json:"baseType,omitempty"`
+	// Position of the member in the go structure and its raw struct tag
+	Pos token.Pos `json:"-"`
+	Tag string    `json:"-"`
+	// Type of the member as the type checker sees it, nil for members of models that are not
+	// loaded from their source
+	Type types.Type `json:"-"`
+}
+
+type ObjectMemberAndInfo struct {
+	ObjectMembersInfo
+	MemberName string
+}
+
+// This structure represents the objects that are generated directly from go files instead of yang models
+type RawObjSrcInfo struct {
+	Owner string `json:"owner"`
+}
+
+// Model is the in-memory description of one package of model objects. It is all the
+// generator needs to produce code, so it can be loaded from the model sources with
+// LoadObjects/LoadActions or be put together by the caller.
+type Model struct {
+	// Objects as listed in genObjectConfig.json/genObjectAction.json, keyed by object name
+	Objects map[string]ObjectInfoJson
+	// Members of every object whose go structure is known, keyed by object name and then member name
+	Members map[string]map[string]ObjectMembersInfo
+	// Set when the objects are actions rather than config objects
+	Actions bool
+	// Hand coded source files and their owners as listed in goObjInfo.json/goActionInfo.json
+	Sources map[string]RawObjSrcInfo
+}
+
+func NewModel() *Model {
+	return &Model{
+		Objects: make(map[string]ObjectInfoJson),
+		Members: make(map[string]map[string]ObjectMembersInfo),
+		Sources: make(map[string]RawObjSrcInfo),
+	}
+}
+
+// LoadObjects reads the config object model from objFileBase. Objects listed in genObjectConfig.json
+// are merged with the hand coded objects listed in goObjInfo.json and the go structure of each of them
+// is parsed for its members.
+// Failures in single objects do not stop the loading. They are returned together as an ErrorList
+// along with the model holding everything that could be loaded.
+func LoadObjects(fset *token.FileSet, objFileBase string) (*Model, error) {
+	var goSrcsMap map[string]RawObjSrcInfo
+	var errs ErrorList
+	model := NewModel()
+	finder := newStructFinder(fset)
+
+	objJsonFile := filepath.Join(objFileBase, OBJECT_CONFIG_FILE)
+	bytes, err := ioutil.ReadFile(objJsonFile)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintln("Error in reading Object json file", objJsonFile, err))
+	}
+	err = json.Unmarshal(bytes, &model.Objects)
+	if err != nil {
+		errs.Add(jsonError(objJsonFile, bytes, err), "", PHASE_LOAD)
+	}
+	if model.Objects == nil {
+		model.Objects = make(map[string]ObjectInfoJson)
+	}
+
+	//
+	// Files generated from yang models are already listed in right format in genObjectConfig.json
+	// However in some cases we have only go objects. Read the goObjInfo.json file and generate a similar
+	// structure here.
+	//
+	goObjSources := filepath.Join(objFileBase, OBJECT_SRC_INFO_FILE)
+	bytes, err = ioutil.ReadFile(goObjSources)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintln("Error in reading Object configuration file", goObjSources, err))
+	}
+	err = json.Unmarshal(bytes, &goSrcsMap)
+	if err != nil {
+		errs.Add(jsonError(goObjSources, bytes, err), "", PHASE_LOAD)
+	}
+	for goSrcFile, srcInfo := range goSrcsMap {
+		model.Sources[goSrcFile] = srcInfo
+	}
+
+	for _, goSrcFile := range sortedSrcFiles(goSrcsMap) {
+		ownerName := goSrcsMap[goSrcFile]
+		logger.Debug("Reading hand coded objects", LOG_KEY_PHASE, PHASE_LOAD, LOG_KEY_FILE, goSrcFile, LOG_KEY_OWNER, ownerName.Owner)
+		err = generateHandCodedObjectsInformation(finder, model.Objects, objFileBase, goSrcFile, ownerName.Owner)
+		errs.Add(err, "", PHASE_LOAD)
+	}
+
+	errs.Add(model.loadMembers(finder, objFileBase), "", PHASE_MEMBERS)
+	return model, errs.Err()
+}
+
+// LoadActions reads the action model from actionFileBase, the same way LoadObjects does for config objects
+func LoadActions(fset *token.FileSet, actionFileBase string) (*Model, error) {
+	var goActionSrcsMap map[string]RawObjSrcInfo
+	var errs ErrorList
+	model := NewModel()
+	model.Actions = true
+	finder := newStructFinder(fset)
+
+	goActionSources := filepath.Join(actionFileBase, ACTION_SRC_INFO_FILE)
+	bytes, err := ioutil.ReadFile(goActionSources)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintln("Error in reading action object file", goActionSources, err))
+	}
+	err = json.Unmarshal(bytes, &goActionSrcsMap)
+	if err != nil {
+		errs.Add(jsonError(goActionSources, bytes, err), "", PHASE_LOAD)
+	}
+	for goSrcFile, srcInfo := range goActionSrcsMap {
+		model.Sources[goSrcFile] = srcInfo
+	}
+
+	// genObjectAction.json is optional, it only lists actions that are not hand coded
+	actionJsonFile := filepath.Join(actionFileBase, ACTION_CONFIG_FILE)
+	bytes, err = ioutil.ReadFile(actionJsonFile)
+	if err == nil {
+		err = json.Unmarshal(bytes, &model.Objects)
+		if err != nil {
+			errs.Add(jsonError(actionJsonFile, bytes, err), "", PHASE_LOAD)
+		}
+	}
+	if model.Objects == nil {
+		model.Objects = make(map[string]ObjectInfoJson)
+	}
+
+	for _, goSrcFile := range sortedSrcFiles(goActionSrcsMap) {
+		ownerName := goActionSrcsMap[goSrcFile]
+		logger.Debug("Reading hand coded actions", LOG_KEY_PHASE, PHASE_LOAD, LOG_KEY_FILE, goSrcFile, LOG_KEY_OWNER, ownerName.Owner)
+		err = generateHandCodedActionsInformation(finder, model.Objects, actionFileBase, goSrcFile, ownerName.Owner)
+		errs.Add(err, "", PHASE_LOAD)
+	}
+
+	errs.Add(model.loadMembers(finder, actionFileBase), "", PHASE_MEMBERS)
+	return model, errs.Err()
+}
+
+// loadMembers parses the source file of every object and collects the members of its go structure,
+// including those of the structures embedded in it.
+// Objects whose members can not be determined are left out of model.Members.
+func (model *Model) loadMembers(finder *structFinder, objFileBase string) error {
+	var errs ErrorList
+	fset := finder.fset
+	reported := make(map[string]bool)
+	for _, name := range sortedObjectNames(model.Objects) {
+		obj := model.Objects[name]
+		srcFile := filepath.Join(objFileBase, obj.SrcFile)
+		logger.Debug("Reading members", LOG_KEY_PHASE, PHASE_MEMBERS, LOG_KEY_OBJECT, name, LOG_KEY_FILE, srcFile)
+		f, err := finder.parseFile(srcFile)
+		if err != nil {
+			// A file that does not parse is reported once, not for every object in it
+			if !reported[srcFile] {
+				errs.Add(err, "", PHASE_MEMBERS)
+				reported[srcFile] = true
+			}
+			continue
+		}
+
+		// The structure may be declared in another file of the package
+		typ, declFile := finder.findType(f, name)
+		var str *ast.StructType
+		if typ != nil {
+			str, _ = typ.Type.(*ast.StructType)
+		}
+		if str == nil {
+			errs.Add(newError(fset, token.NoPos, srcFile, name, PHASE_MEMBERS, errors.New("Structure of the object is not defined in its package")), name, PHASE_MEMBERS)
+			continue
+		}
+		obj.Pos = typ.Pos()
+		if checked := finder.filePackage(declFile); checked.pkg != nil {
+			obj.PkgPath = checked.pkg.Path()
+		}
+		model.Objects[name] = obj
+		fields, err := finder.fields(name, str, declFile)
+		if err != nil {
+			errs.Add(err, name, PHASE_MEMBERS)
+			continue
+		}
+		members, err := generateMembersInfoForAllObjects(finder, name, fields)
+		if err == nil {
+			err = finder.checkAttrFields(name, typ, declFile, obj.ConvertObjectMembersMapToOrderedSlice(members))
+		}
+		if err != nil {
+			errs.Add(err, name, PHASE_MEMBERS)
+			continue
+		}
+		model.Members[name] = members
+	}
+	return errs.Err()
+}
+
+// tagInt parses the numeric value of tag key. Values that do not fit an int are clamped as before.
+func tagInt(key string, value string) (int, error) {
+	n, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil && err.(*strconv.NumError).Err != strconv.ErrRange {
+		return n, errors.New(fmt.Sprintln("Invalid value", strconv.Quote(strings.TrimSpace(value)), "for tag", key))
+	}
+	return n, nil
+}
+
+// getSpecialTagsForAttribute sets the attributes of a member from its struct tag, attrTags being
+// the tag as written in the go source
+func getSpecialTagsForAttribute(attrTags string, attrInfo *ObjectMembersInfo) error {
+	tag, err := parseTagLiteral(attrTags)
+	if err != nil {
+		return err
+	}
+	for _, entry := range tag {
+		value := strings.TrimSpace(entry.Value)
+		switch entry.Name {
+		case "SNAPROUTE":
+			attrInfo.IsKey = true
+		case "DESCRIPTION":
+			attrInfo.Description = value
+		case "SELECTION":
+			tmpSlice := strings.Split(value, "/")
+			for _, val := range tmpSlice {
+				val = strings.TrimSpace(val)
+				// Selections may be quoted one by one, as in "UP"/"DOWN"
+				if text, err := strconv.Unquote(val); err == nil && strings.HasPrefix(val, "\"") {
+					val = text
+				}
+				attrInfo.Selections = append(attrInfo.Selections, val)
+			}
+		case "DEFAULT":
+			attrInfo.DefaultVal = value
+			attrInfo.IsDefaultSet = true
+		case "ACCELERATED":
+			attrInfo.Accelerated = true
+		case "MIN":
+			if attrInfo.Min, err = tagInt(entry.Name, value); err != nil {
+				return err
+			}
+		case "MAX":
+			if attrInfo.Max, err = tagInt(entry.Name, value); err != nil {
+				return err
+			}
+		case "RANGE":
+			if attrInfo.Ranges, err = parseRanges(value); err != nil {
+				return err
+			}
+			// Min and Max hold the overall bounds for the consumers that do not know about ranges
+			low, high := rangesSpan(attrInfo.Ranges)
+			if low != "" {
+				attrInfo.Min, _ = tagInt(entry.Name, string(low))
+			}
+			if high != "" {
+				attrInfo.Max, _ = tagInt(entry.Name, string(high))
+			}
+		case "STRLEN", "LEN":
+			if attrInfo.Len, err = tagInt(entry.Name, value); err != nil {
+				return err
+			}
+		case "QPARAM":
+			attrInfo.QueryParam = value
+		case "USESTATEDB":
+			attrInfo.UsesStateDB = true
+		case "AUTOCREATE":
+			attrInfo.AutoCreate = true
+		case "AUTODISCOVER":
+			attrInfo.AutoDiscover = true
+		case "PARENT":
+			attrInfo.Parent = value
+			attrInfo.IsParentSet = true
+		case "UNIT":
+			attrInfo.Unit = value
+		}
+	}
+	return nil
+}
+
+// generateMembersInfoForAllObjects collects the members of object objName from the fields of its
+// go structure, as returned by structFinder.fields. Named types of basic types are resolved to
+// these and constants given as DEFAULT to their values. Members of a type the generator can not
+// handle are reported with the position of the field.
+func generateMembersInfoForAllObjects(finder *structFinder, objName string, fields []structField) (map[string]ObjectMembersInfo, error) {
+	var errs ErrorList
+	fset := finder.fset
+	var objMembers map[string]ObjectMembersInfo
+	objMembers = make(map[string]ObjectMembersInfo, 1)
+
+	for _, fld := range fields {
+		if fld.Names != nil {
+			varName := fld.Name.Name
+			fieldError := func(err error) {
+				errs.Add(newError(fset, fld.Pos(), "", objName, PHASE_MEMBERS, errors.New(fmt.Sprintln("Member", varName+":", strings.TrimSpace(err.Error())))), objName, PHASE_MEMBERS)
+			}
+			if other, exist := objMembers[varName]; exist {
+				// Go would hide one of the two, the generated code needs every member to be reachable
+				fieldError(errors.New(fmt.Sprintln("Also declared at", fset.Position(other.Pos))))
+				continue
+			}
+			typ, err := newMemberType(fld.Type, fileImports(fld.File))
+			if err != nil {
+				fieldError(err)
+				continue
+			}
+			if fld.PkgPath != "" {
+				typ.qualify(fld.File.Name.Name, fld.PkgPath)
+			}
+			info := ObjectMembersInfo{}
+			if info.Type, err = finder.exprType(fld.Type, fld.File); err != nil {
+				fieldError(err)
+				continue
+			}
+			info.Position = fld.Position
+			info.Pos = fld.Pos()
+			info.Embedded = fld.Embedded
+			elemType := typ
+			if typ.Kind == TYPE_SLICE {
+				info.IsArray = true
+				elemType = typ.Elem
+			}
+			info.VarType = elemType.String()
+			if elemType.Kind != TYPE_NAMED || elemType.Package != "" {
+				info.GoType = typ
+			}
+			if elemType.Kind == TYPE_NAMED {
+				elemExpr := fld.Type
+				if info.IsArray {
+					elemExpr = ast.Unparen(elemExpr).(*ast.ArrayType).Elt
+				}
+				base, err := finder.basicType(elemExpr, fld.File)
+				if err != nil {
+					fieldError(err)
+					continue
+				}
+				if base != info.VarType {
+					info.BaseType = base
+				}
+			}
+			if fld.Tag != nil {
+				info.Tag = fld.Tag.Value
+				if err := getSpecialTagsForAttribute(fld.Tag.Value, &info); err != nil {
+					fieldError(err)
+					continue
+				}
+			}
+			if info.IsDefaultSet {
+				// A default naming a constant stands for its value
+				val, err := finder.defaultConst(info.DefaultVal, fld.File, info.baseType() == "string")
+				if err != nil {
+					fieldError(err)
+					continue
+				}
+				if val != nil {
+					info.DefaultVal = constText(val)
+				}
+			}
+			objMembers[varName] = info
+		}
+	}
+	return objMembers, errs.Err()
+}
+
+// generateHandCodedObjectsInformation adds the objects defined in a hand coded go file to objMap.
+// The object attributes may be tagged on a member of an embedded structure as well.
+func generateHandCodedObjectsInformation(finder *structFinder, objMap map[string]ObjectInfoJson, objFileBase string, srcFile string, owner string) error {
+	fset := finder.fset
+	// Now read the contents of Hand coded Go structures
+	f, err := finder.parseFile(filepath.Join(objFileBase, srcFile))
+	if err != nil {
+		return err
+	}
+
+	for _, dec := range f.Decls {
+		tk, ok := dec.(*ast.GenDecl)
+		if ok {
+			for _, spec := range tk.Specs {
+				switch spec.(type) {
+				case *ast.TypeSpec:
+					obj := ObjectInfoJson{}
+					obj.SrcFile = srcFile
+					obj.Owner = owner
+					typ := spec.(*ast.TypeSpec)
+					str, ok := typ.Type.(*ast.StructType)
+					if ok == true {
+						// Embedding errors are reported with the members of the object
+						fields, _ := finder.fields(typ.Name.Name, str, f)
+						for _, fld := range fields {
+							if fld.Names != nil {
+								switch fld.Type.(type) {
+								case *ast.Ident:
+									if fld.Tag != nil {
+										tag, err := parseTagLiteral(fld.Tag.Value)
+										if err != nil {
+											return newError(fset, fld.Pos(), "", typ.Name.Name, PHASE_LOAD,
+												errors.New(fmt.Sprintln("Member", fld.Name.Name+":", strings.TrimSpace(err.Error()))))
+										}
+										if _, ok := tag.Lookup("SNAPROUTE"); ok {
+											for _, entry := range tag {
+												switch entry.Name {
+												case "ACCESS":
+													obj.Access = strings.TrimSpace(entry.Value)
+												case "MULTIPLICITY":
+													obj.Multiplicity = strings.TrimSpace(entry.Value)
+												case "ACCELERATED":
+													obj.Accelerated = true
+												case "USESTATEDB":
+													obj.UsesStateDB = true
+												case "AUTOCREATE":
+													obj.AutoCreate = true
+												case "AUTODISCOVER":
+													obj.AutoDiscover = true
+												case "LENIENTUNMARSHAL":
+													obj.LenientUnmarshal = true
+												}
+											}
+										}
+									}
+								}
+							}
+						}
+						logger.Debug("Found hand coded object", LOG_KEY_PHASE, PHASE_LOAD, LOG_KEY_OBJECT, typ.Name.Name,
+							LOG_KEY_OWNER, owner, LOG_KEY_FILE, obj.SrcFile)
+						objMap[typ.Name.Name] = obj
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// generateHandCodedActionsInformation adds the actions defined in a hand coded go file to actionMap
+func generateHandCodedActionsInformation(finder *structFinder, actionMap map[string]ObjectInfoJson, actionFileBase string, srcFile string, owner string) error {
+	// Now read the contents of Hand coded Go structures
+	f, err := finder.parseFile(filepath.Join(actionFileBase, srcFile))
+	if err != nil {
+		return err
+	}
+
+	for _, dec := range f.Decls {
+		tk, ok := dec.(*ast.GenDecl)
+		if ok {
+			for _, spec := range tk.Specs {
+				switch spec.(type) {
+				case *ast.TypeSpec:
+					typ := spec.(*ast.TypeSpec)
+					action := ObjectInfoJson{}
+					action.SrcFile = srcFile
+					action.Owner = owner
+					action.Access = "x"
+					actionMap[typ.Name.Name] = action
+				}
+			}
+		}
+	}
+	return nil
+}
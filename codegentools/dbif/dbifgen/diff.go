@@ -0,0 +1,259 @@
+package dbifgen
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+const (
+	DIFF_CONTEXT_LINES = 3
+	MAX_DIFF_EDITS     = 4000
+)
+
+type diffOp struct {
+	kind byte // ' ', '-' or '+'
+	a    int  // line index in a for ' ' and '-'
+	b    int  // line index in b for ' ' and '+'
+}
+
+// splitLines splits data into lines keeping the line terminators, so that a missing
+// newline at the end of the file shows up in the diff
+func splitLines(data []byte) []string {
+	if len(data) == 0 {
+		return nil
+	}
+	lines := strings.SplitAfter(string(data), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// UnifiedDiff returns the differences between a and b in unified diff format with three
+// lines of context. An empty string is returned when a and b are equal.
+func UnifiedDiff(oldName, newName string, a, b []byte) string {
+	if bytes.Equal(a, b) {
+		return ""
+	}
+	aLines := splitLines(a)
+	bLines := splitLines(b)
+	ops := diffLines(aLines, bLines)
+
+	var out bytes.Buffer
+	out.WriteString("--- " + oldName + "\n")
+	out.WriteString("+++ " + newName + "\n")
+	for start := 0; start < len(ops); {
+		// Find the next change and the range of ops forming its hunk
+		for start < len(ops) && ops[start].kind == ' ' {
+			start++
+		}
+		if start == len(ops) {
+			break
+		}
+		hunkStart := start - DIFF_CONTEXT_LINES
+		if hunkStart < 0 {
+			hunkStart = 0
+		}
+		end := start
+		for end < len(ops) {
+			if ops[end].kind != ' ' {
+				end++
+				continue
+			}
+			next := end
+			for next < len(ops) && ops[next].kind == ' ' {
+				next++
+			}
+			if next == len(ops) || next-end > 2*DIFF_CONTEXT_LINES {
+				break
+			}
+			end = next
+		}
+		hunkEnd := end + DIFF_CONTEXT_LINES
+		if hunkEnd > len(ops) {
+			hunkEnd = len(ops)
+		}
+		writeHunk(&out, ops[hunkStart:hunkEnd], aLines, bLines)
+		start = hunkEnd
+	}
+	return out.String()
+}
+
+func writeHunk(out *bytes.Buffer, ops []diffOp, aLines, bLines []string) {
+	aStart, bStart := -1, -1
+	aCount, bCount := 0, 0
+	for _, op := range ops {
+		switch op.kind {
+		case ' ':
+			if aStart < 0 {
+				aStart = op.a
+			}
+			if bStart < 0 {
+				bStart = op.b
+			}
+			aCount++
+			bCount++
+		case '-':
+			if aStart < 0 {
+				aStart = op.a
+			}
+			aCount++
+		case '+':
+			if bStart < 0 {
+				bStart = op.b
+			}
+			bCount++
+		}
+	}
+	fmt.Fprintf(out, "@@ -%s +%s @@\n", hunkRange(aStart, aCount, ops, true), hunkRange(bStart, bCount, ops, false))
+	for _, op := range ops {
+		var line string
+		switch op.kind {
+		case ' ', '-':
+			line = aLines[op.a]
+		case '+':
+			line = bLines[op.b]
+		}
+		out.WriteByte(op.kind)
+		out.WriteString(line)
+		if !strings.HasSuffix(line, "\n") {
+			out.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+func hunkRange(start, count int, ops []diffOp, old bool) string {
+	if count == 0 {
+		// An empty range refers to the line before the change
+		for _, op := range ops {
+			if old {
+				return fmt.Sprintf("%d,0", op.a)
+			}
+			return fmt.Sprintf("%d,0", op.b)
+		}
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+// diffLines computes the shortest edit script turning a into b using Myers' algorithm.
+// The returned ops carry, for every op, the current position in both a and b.
+func diffLines(a, b []string) []diffOp {
+	// Common prefix and suffix are kept out of the search
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	aMid := a[prefix : len(a)-suffix]
+	bMid := b[prefix : len(b)-suffix]
+
+	var ops []diffOp
+	for i := 0; i < prefix; i++ {
+		ops = append(ops, diffOp{' ', i, i})
+	}
+	for _, op := range myers(aMid, bMid) {
+		ops = append(ops, diffOp{op.kind, op.a + prefix, op.b + prefix})
+	}
+	for i := 0; i < suffix; i++ {
+		ops = append(ops, diffOp{' ', len(a) - suffix + i, len(b) - suffix + i})
+	}
+	return ops
+}
+
+// myers finds the shortest edit script between a and b. Inputs that need more than
+// MAX_DIFF_EDITS edits are reported as a full replacement to bound memory use.
+func myers(a, b []string) []diffOp {
+	n, m := len(a), len(b)
+	max := n + m
+	if max == 0 {
+		return nil
+	}
+	if max > MAX_DIFF_EDITS {
+		max = MAX_DIFF_EDITS
+	}
+	offset := max + 1
+	v := make([]int, 2*max+3)
+	// trace[d] holds v for diagonals -(d+1)..d+1 as it was before step d
+	var trace [][]int
+	found := false
+	for d := 0; d <= max && !found; d++ {
+		trace = append(trace, append([]int(nil), v[offset-d-1:offset+d+2]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				found = true
+				break
+			}
+		}
+	}
+	if !found {
+		return replaceAll(a, b)
+	}
+
+	// Walk the trace backwards to recover the edit script
+	var ops []diffOp
+	x, y := n, m
+	for d := len(trace) - 1; d > 0; d-- {
+		v := trace[d]
+		at := func(k int) int { return v[k+d+1] }
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := at(prevK)
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x--
+			y--
+			ops = append(ops, diffOp{' ', x, y})
+		}
+		if x == prevX {
+			y--
+			ops = append(ops, diffOp{'+', x, y})
+		} else {
+			x--
+			ops = append(ops, diffOp{'-', x, y})
+		}
+	}
+	for x > 0 && y > 0 {
+		x--
+		y--
+		ops = append(ops, diffOp{' ', x, y})
+	}
+	for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+		ops[i], ops[j] = ops[j], ops[i]
+	}
+	return ops
+}
+
+func replaceAll(a, b []string) []diffOp {
+	var ops []diffOp
+	for i := range a {
+		ops = append(ops, diffOp{'-', i, 0})
+	}
+	for j := range b {
+		ops = append(ops, diffOp{'+', len(a), j})
+	}
+	return ops
+}
@@ -0,0 +1,208 @@
+package dbifgen
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	numbers := func(from, to int, replace map[int]string) string {
+		var lines []string
+		for i := from; i <= to; i++ {
+			line := fmt.Sprint(i)
+			if text, exist := replace[i]; exist {
+				line = text
+			}
+			lines = append(lines, line+"\n")
+		}
+		return strings.Join(lines, "")
+	}
+	tests := []struct {
+		name string
+		a, b string
+		want string
+	}{
+		{
+			name: "equal",
+			a:    "a\nb\n",
+			b:    "a\nb\n",
+			want: "",
+		},
+		{
+			name: "new file",
+			a:    "",
+			b:    "x\ny\n",
+			want: "@@ -0,0 +1,2 @@\n+x\n+y\n",
+		},
+		{
+			name: "removed file",
+			a:    "x\ny\n",
+			b:    "",
+			want: "@@ -1,2 +0,0 @@\n-x\n-y\n",
+		},
+		{
+			name: "single line",
+			a:    "x\n",
+			b:    "y\n",
+			want: "@@ -1 +1 @@\n-x\n+y\n",
+		},
+		{
+			name: "no newline at end of both",
+			a:    "a\nb",
+			b:    "a\nc",
+			want: "@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+c\n\\ No newline at end of file\n",
+		},
+		{
+			name: "newline removed",
+			a:    "a\nb\n",
+			b:    "a\nb",
+			want: "@@ -1,2 +1,2 @@\n a\n-b\n+b\n\\ No newline at end of file\n",
+		},
+		{
+			name: "newline added",
+			a:    "a\nb",
+			b:    "a\nb\n",
+			want: "@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+b\n",
+		},
+		{
+			name: "separate hunks",
+			a:    numbers(1, 20, nil),
+			b:    numbers(1, 20, map[int]string{2: "X", 18: "Y"}),
+			want: "@@ -1,5 +1,5 @@\n 1\n-2\n+X\n 3\n 4\n 5\n" +
+				"@@ -15,6 +15,6 @@\n 15\n 16\n 17\n-18\n+Y\n 19\n 20\n",
+		},
+		{
+			name: "hunks joined by six lines of context",
+			a:    numbers(1, 20, nil),
+			b:    numbers(1, 20, map[int]string{2: "X", 9: "Y"}),
+			want: "@@ -1,12 +1,12 @@\n 1\n-2\n+X\n 3\n 4\n 5\n 6\n 7\n 8\n-9\n+Y\n 10\n 11\n 12\n",
+		},
+		{
+			name: "hunks apart by seven lines of context",
+			a:    numbers(1, 20, nil),
+			b:    numbers(1, 20, map[int]string{2: "X", 10: "Y"}),
+			want: "@@ -1,5 +1,5 @@\n 1\n-2\n+X\n 3\n 4\n 5\n" +
+				"@@ -7,7 +7,7 @@\n 7\n 8\n 9\n-10\n+Y\n 11\n 12\n 13\n",
+		},
+		{
+			name: "insertion",
+			a:    numbers(1, 6, nil),
+			b:    strings.Replace(numbers(1, 6, nil), "3\n", "3\nnew\n", 1),
+			want: "@@ -1,6 +1,7 @@\n 1\n 2\n 3\n+new\n 4\n 5\n 6\n",
+		},
+	}
+	for _, test := range tests {
+		want := test.want
+		if want != "" {
+			want = "--- old\n+++ new\n" + want
+		}
+		if got := UnifiedDiff("old", "new", []byte(test.a), []byte(test.b)); got != want {
+			t.Errorf("%s: UnifiedDiff = \n%s\nwant\n%s", test.name, got, want)
+		}
+	}
+}
+
+// applyOps returns the lines of b the edit script ops makes of a, and checks that the script
+// walks both a and b in order
+func applyOps(t *testing.T, ops []diffOp, a, b []string) []string {
+	var out []string
+	x, y := 0, 0
+	for _, op := range ops {
+		if op.a != x || op.b != y {
+			t.Fatalf("Op %c at %d,%d, expected %d,%d", op.kind, op.a, op.b, x, y)
+		}
+		switch op.kind {
+		case ' ':
+			if a[x] != b[y] {
+				t.Fatalf("Context line %q of a is %q in b", a[x], b[y])
+			}
+			out = append(out, a[x])
+			x++
+			y++
+		case '-':
+			x++
+		case '+':
+			out = append(out, b[y])
+			y++
+		}
+	}
+	if x != len(a) {
+		t.Fatalf("Script ends at line %d of %d of a", x, len(a))
+	}
+	return out
+}
+
+func TestMyers(t *testing.T) {
+	tests := []struct {
+		a, b  string
+		edits int
+	}{
+		{"", "", 0},
+		{"abc", "abc", 0},
+		{"", "abc", 3},
+		{"abc", "", 3},
+		{"abcabba", "cbabac", 5},
+		{"abcd", "acbd", 2},
+		{"xaxbxc", "abc", 3},
+	}
+	for _, test := range tests {
+		a, b := strings.Split(test.a, ""), strings.Split(test.b, "")
+		ops := myers(a, b)
+		if got := strings.Join(applyOps(t, ops, a, b), ""); got != test.b {
+			t.Errorf("myers(%q, %q) makes %q", test.a, test.b, got)
+		}
+		edits := 0
+		for _, op := range ops {
+			if op.kind != ' ' {
+				edits++
+			}
+		}
+		if edits != test.edits {
+			t.Errorf("myers(%q, %q) has %d edits, want %d", test.a, test.b, edits, test.edits)
+		}
+	}
+}
+
+func TestMyersMaxEdits(t *testing.T) {
+	lines := func(prefix string, n int) []string {
+		var lines []string
+		for i := 0; i < n; i++ {
+			lines = append(lines, fmt.Sprint(prefix, i, "\n"))
+		}
+		return lines
+	}
+
+	// Long inputs that differ in few lines are still diffed
+	a := lines("a", MAX_DIFF_EDITS)
+	b := append(append(append([]string(nil), a[:10]...), "new\n"), a[10:]...)
+	ops := myers(a, b)
+	applyOps(t, ops, a, b)
+	if len(ops) != len(a)+1 {
+		t.Errorf("myers of an insertion into %d lines has %d ops, want %d", len(a), len(ops), len(a)+1)
+	}
+
+	// More than MAX_DIFF_EDITS edits replace all of a with all of b, even the line they have in
+	// common, which would take 2n-2 edits
+	n := MAX_DIFF_EDITS/2 + 2
+	a, b = lines("a", n), lines("b", n)
+	b[n/2] = a[n/2]
+	ops = myers(a, b)
+	applyOps(t, ops, a, b)
+	if len(ops) != 2*n {
+		t.Fatalf("Replacement of %d lines has %d ops, want %d", n, len(ops), 2*n)
+	}
+	for i, op := range ops {
+		want := byte('-')
+		if i >= n {
+			want = '+'
+		}
+		if op.kind != want {
+			t.Fatalf("Op %d of the replacement is %c, want %c", i, op.kind, want)
+		}
+	}
+	want := fmt.Sprintf("--- old\n+++ new\n@@ -1,%d +1,%d @@\n", n, n)
+	if got := UnifiedDiff("old", "new", []byte(strings.Join(a, "")), []byte(strings.Join(b, ""))); !strings.HasPrefix(got, want) {
+		t.Errorf("UnifiedDiff of a full replacement starts with %q, want %q", got[:len(want)], want)
+	}
+}
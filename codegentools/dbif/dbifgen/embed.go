@@ -0,0 +1,310 @@
+package dbifgen
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// structFinder parses model source files and finds the go structures of the objects and of the
+// types embedded in them. Every file is parsed once, the types of every file and package are
+// indexed once and every package is type-checked once, see typecheck.go.
+type structFinder struct {
+	fset *token.FileSet
+	// Parsed files by absolute path, and the errors of those that do not parse. Files are also
+	// parsed while packages are loaded, which parses them concurrently.
+	mu        sync.Mutex
+	files     map[string]*ast.File
+	parseErrs map[string]error
+	// Parsed go files of a package directory
+	pkgFiles map[string][]*ast.File
+	// Types declared in a file and in a package directory, by name
+	fileTypes map[*ast.File]map[string]typeDecl
+	pkgTypes  map[string]map[string]typeDecl
+	// Type-checked packages by directory
+	checked map[string]*checkedPackage
+}
+
+func newStructFinder(fset *token.FileSet) *structFinder {
+	return &structFinder{
+		fset:      fset,
+		files:     make(map[string]*ast.File),
+		parseErrs: make(map[string]error),
+		pkgFiles:  make(map[string][]*ast.File),
+		fileTypes: make(map[*ast.File]map[string]typeDecl),
+		pkgTypes:  make(map[string]map[string]typeDecl),
+		checked:   make(map[string]*checkedPackage),
+	}
+}
+
+// parseFile returns the parsed file at path, or the error parsing it
+func (finder *structFinder) parseFile(path string) (*ast.File, error) {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	finder.mu.Lock()
+	defer finder.mu.Unlock()
+	if err, failed := finder.parseErrs[path]; failed {
+		return nil, err
+	}
+	if f, seen := finder.files[path]; seen {
+		return f, nil
+	}
+	f, err := parser.ParseFile(finder.fset, path, nil, parser.ParseComments)
+	if err != nil {
+		finder.parseErrs[path] = err
+		return nil, err
+	}
+	finder.files[path] = f
+	return f, nil
+}
+
+// packageFiles returns the go files of the package in dir that parse. Test files and the files
+// written by the generator are left out.
+func (finder *structFinder) packageFiles(dir string) []*ast.File {
+	if files, seen := finder.pkgFiles[dir]; seen {
+		return files
+	}
+	var files []*ast.File
+	names, _ := filepath.Glob(filepath.Join(dir, "*.go"))
+	for _, name := range names {
+		if strings.HasSuffix(name, "_test.go") || isGeneratedSrcFile(name) {
+			continue
+		}
+		if f, err := finder.parseFile(name); err == nil {
+			files = append(files, f)
+		}
+	}
+	finder.pkgFiles[dir] = files
+	return files
+}
+
+func isGeneratedSrcFile(name string) bool {
+	for _, pattern := range GeneratedSrcPatterns {
+		if matched, _ := filepath.Match(pattern, filepath.Base(name)); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// packageDir returns the directory of the package with import path pkgPath, imported from the
+// directory srcDir. The packages are looked up in the GOPATH of the models, see modelGopath.
+func packageDir(pkgPath string, srcDir string) (string, error) {
+	ctxt := build.Default
+	ctxt.GOPATH = modelGopath(srcDir)
+	// With a file system hook set, go/build looks in GOROOT and GOPATH itself instead of asking
+	// the go command, whose answer depends on GO111MODULE and on the current directory
+	ctxt.JoinPath = filepath.Join
+	pkg, err := ctxt.Import(pkgPath, srcDir, build.FindOnly)
+	if err != nil {
+		return "", errors.New(fmt.Sprintln("Package", pkgPath, "is not found:", err))
+	}
+	return pkg.Dir, nil
+}
+
+// modelGopath returns the GOPATH the models in dir are built with: the tree dir is in, as the
+// model sources are usually built with it in GOPATH, followed by the GOPATH of the environment
+func modelGopath(dir string) string {
+	if srcTree := srcTreeDir(dir); srcTree != "" {
+		return filepath.Dir(srcTree) + string(os.PathListSeparator) + build.Default.GOPATH
+	}
+	return build.Default.GOPATH
+}
+
+// srcTreeDir returns the nearest directory named src that dir is in, or empty if there is none
+func srcTreeDir(dir string) string {
+	for ; filepath.Dir(dir) != dir; dir = filepath.Dir(dir) {
+		if filepath.Base(dir) == "src" {
+			return dir
+		}
+	}
+	return ""
+}
+
+// typeDecl is the declaration of a type and the file declaring it
+type typeDecl struct {
+	spec *ast.TypeSpec
+	file *ast.File
+}
+
+// declaredTypes returns the types declared at the top level of f by name
+func (finder *structFinder) declaredTypes(f *ast.File) map[string]typeDecl {
+	if decls, seen := finder.fileTypes[f]; seen {
+		return decls
+	}
+	decls := make(map[string]typeDecl)
+	for _, dec := range f.Decls {
+		tk, ok := dec.(*ast.GenDecl)
+		if !ok {
+			continue
+		}
+		for _, spec := range tk.Specs {
+			if typ, ok := spec.(*ast.TypeSpec); ok {
+				if _, exist := decls[typ.Name.Name]; !exist {
+					decls[typ.Name.Name] = typeDecl{spec: typ, file: f}
+				}
+			}
+		}
+	}
+	finder.fileTypes[f] = decls
+	return decls
+}
+
+// packageTypes returns the types declared in the package in dir by name. A type declared in
+// several files is taken from the first one.
+func (finder *structFinder) packageTypes(dir string) map[string]typeDecl {
+	if decls, seen := finder.pkgTypes[dir]; seen {
+		return decls
+	}
+	decls := make(map[string]typeDecl)
+	for _, f := range finder.packageFiles(dir) {
+		for name, decl := range finder.declaredTypes(f) {
+			if _, exist := decls[name]; !exist {
+				decls[name] = decl
+			}
+		}
+	}
+	finder.pkgTypes[dir] = decls
+	return decls
+}
+
+// findType returns the declaration of the type name and the file declaring it. The type is looked
+// up in f first and then in the other files of the package of f.
+func (finder *structFinder) findType(f *ast.File, name string) (*ast.TypeSpec, *ast.File) {
+	if decl, exist := finder.declaredTypes(f)[name]; exist {
+		return decl.spec, decl.file
+	}
+	dir := filepath.Dir(finder.fset.File(f.Pos()).Name())
+	decl := finder.packageTypes(dir)[name]
+	return decl.spec, decl.file
+}
+
+// embeddedStruct returns the go structure of the type of the embedded field fld of a structure
+// declared in f, the file declaring it and the name of the type. The structure is nil if the type
+// is not a structure, such as the ConfigObj interface. The last result is the import path of the
+// package of the type if that is not the package of f.
+func (finder *structFinder) embeddedStruct(fld *ast.Field, f *ast.File) (*ast.StructType, *ast.File, string, string, error) {
+	typeName := types.ExprString(fld.Type)
+	var typ *ast.TypeSpec
+	var declFile *ast.File
+	var pkgPath string
+	switch expr := fld.Type.(type) {
+	case *ast.Ident:
+		typ, declFile = finder.findType(f, expr.Name)
+	case *ast.SelectorExpr:
+		pkg, ok := expr.X.(*ast.Ident)
+		if !ok {
+			return nil, nil, "", "", errors.New(fmt.Sprintln("Embedded type", typeName, "is not supported"))
+		}
+		var exist bool
+		pkgPath, exist = fileImports(f)[pkg.Name]
+		if !exist {
+			return nil, nil, "", "", errors.New(fmt.Sprintln("Package", pkg.Name, "of embedded type", typeName, "is not imported"))
+		}
+		srcFile := finder.fset.File(f.Pos()).Name()
+		dir, err := packageDir(pkgPath, filepath.Dir(srcFile))
+		if err != nil {
+			return nil, nil, "", "", err
+		}
+		decl := finder.packageTypes(dir)[expr.Sel.Name]
+		typ, declFile = decl.spec, decl.file
+	case *ast.StarExpr:
+		return nil, nil, "", "", errors.New(fmt.Sprintln("Embedded pointer", typeName, "is not supported, embed the structure instead"))
+	default:
+		return nil, nil, "", "", errors.New(fmt.Sprintln("Embedded type", typeName, "is not supported"))
+	}
+	if typ == nil {
+		return nil, nil, "", "", errors.New(fmt.Sprintln("Embedded type", typeName, "is not defined"))
+	}
+	str, _ := typ.Type.(*ast.StructType)
+	return str, declFile, typeName, pkgPath, nil
+}
+
+// structField is a named field of an object, declared in the structure of the object or in a
+// structure embedded in it
+type structField struct {
+	*ast.Field
+	// File declaring the field
+	File *ast.File
+	// Embedded type declaring the field, empty for the fields of the object itself
+	Embedded string
+	// Import path of the package declaring the field, empty for the package of the object
+	PkgPath string
+	// Name of the field. A declaration of several names, such as A, B int32, is a field for each
+	// of them, with the same Field.
+	Name     *ast.Ident
+	Position int
+}
+
+// Pos returns the position of the name of the field
+func (field structField) Pos() token.Pos {
+	return field.Name.Pos()
+}
+
+// fields returns the named fields of the structure str of object objName declared in f. The
+// fields of embedded structures take the place of the embedded field, the way encoding/json and
+// the generated code see them.
+//
+// Position is the index of the field in the structure, counting every name of a declaration of
+// several names. The fields of an embedded structure are numbered from the index of the embedded
+// field on, and the fields after it are shifted by their number. An embedded structure without
+// fields, such as baseObj, keeps its index to itself.
+func (finder *structFinder) fields(objName string, str *ast.StructType, f *ast.File) ([]structField, error) {
+	var errs ErrorList
+	var fields []structField
+	position := 0
+	var addFields func(str *ast.StructType, f *ast.File, pkgPath string, embedded []string)
+	addFields = func(str *ast.StructType, f *ast.File, pkgPath string, embedded []string) {
+		for _, fld := range str.Fields.List {
+			for _, name := range fld.Names {
+				field := structField{Field: fld, File: f, PkgPath: pkgPath, Name: name, Position: position}
+				if len(embedded) > 0 {
+					field.Embedded = embedded[len(embedded)-1]
+				}
+				if pkgPath != "" && !ast.IsExported(name.Name) {
+					errs.Add(newError(finder.fset, name.Pos(), "", objName, PHASE_MEMBERS,
+						errors.New(fmt.Sprintln("Member", name.Name, "of embedded type", field.Embedded, "is not exported"))), objName, PHASE_MEMBERS)
+				} else {
+					fields = append(fields, field)
+				}
+				position++
+			}
+			if fld.Names != nil {
+				continue
+			}
+			embStr, embFile, typeName, embPkgPath, err := finder.embeddedStruct(fld, f)
+			for _, outer := range embedded {
+				if err == nil && outer == typeName {
+					err = errors.New(fmt.Sprintln("Embedded type", typeName, "embeds itself"))
+				}
+			}
+			if err != nil {
+				errs.Add(newError(finder.fset, fld.Pos(), "", objName, PHASE_MEMBERS, err), objName, PHASE_MEMBERS)
+			}
+			if err != nil || embStr == nil {
+				// Embedded types other than structures have no members
+				position++
+				continue
+			}
+			if embPkgPath == "" {
+				embPkgPath = pkgPath
+			}
+			start := position
+			addFields(embStr, embFile, embPkgPath, append(embedded[:len(embedded):len(embedded)], typeName))
+			if position == start {
+				position++
+			}
+		}
+	}
+	addFields(str, f, "", nil)
+	return fields, errs.Err()
+}
@@ -0,0 +1,101 @@
+package dbifgen
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/scanner"
+	"go/token"
+	"strings"
+)
+
+// GenError is a failure to load or generate one object. Pos locates the cause in the model
+// sources as precisely as it is known; Pos.Line is 0 when only the file is known.
+type GenError struct {
+	Pos    token.Position
+	Object string
+	Phase  string
+	Err    error
+}
+
+func (e *GenError) Error() string {
+	var prefix string
+	if e.Pos.Filename != "" {
+		prefix = e.Pos.Filename + ":"
+		if e.Pos.Line > 0 {
+			prefix += fmt.Sprintf("%d:", e.Pos.Line)
+		}
+		prefix += " "
+	}
+	if e.Object != "" {
+		prefix += e.Object + ": "
+	}
+	return prefix + strings.TrimSpace(e.Err.Error())
+}
+
+func (e *GenError) Unwrap() error {
+	return e.Err
+}
+
+// ErrorList collects the failures of a run so that they can all be reported at the end
+type ErrorList []*GenError
+
+func (list ErrorList) Error() string {
+	var msgs []string
+	for _, e := range list {
+		msgs = append(msgs, e.Error())
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Add appends err to the list. Errors that are not already a GenError or an ErrorList are
+// attributed to object and phase. Syntax errors from the go parser keep their position.
+func (list *ErrorList) Add(err error, object string, phase string) {
+	switch e := err.(type) {
+	case nil:
+	case *GenError:
+		*list = append(*list, e)
+	case ErrorList:
+		*list = append(*list, e...)
+	case scanner.ErrorList:
+		for _, se := range e {
+			*list = append(*list, &GenError{Pos: se.Pos, Object: object, Phase: phase, Err: errors.New(se.Msg)})
+		}
+	default:
+		*list = append(*list, &GenError{Object: object, Phase: phase, Err: err})
+	}
+}
+
+// Err returns the list as an error, or nil if it is empty
+func (list ErrorList) Err() error {
+	if len(list) == 0 {
+		return nil
+	}
+	return list
+}
+
+// newError returns a GenError located at pos, or only in the file when pos is not valid
+func newError(fset *token.FileSet, pos token.Pos, fileName string, object string, phase string, err error) *GenError {
+	position := token.Position{Filename: fileName}
+	if pos.IsValid() {
+		position = fset.Position(pos)
+	}
+	return &GenError{Pos: position, Object: object, Phase: phase, Err: err}
+}
+
+// jsonError locates an error returned by json.Unmarshal for data read from fileName
+func jsonError(fileName string, data []byte, err error) *GenError {
+	position := token.Position{Filename: fileName}
+	var offset int64 = -1
+	switch e := err.(type) {
+	case *json.SyntaxError:
+		offset = e.Offset
+	case *json.UnmarshalTypeError:
+		offset = e.Offset
+	}
+	if offset >= 0 && offset <= int64(len(data)) {
+		position.Line = bytes.Count(data[:offset], []byte("\n")) + 1
+	}
+	return &GenError{Pos: position, Phase: PHASE_LOAD, Err: errors.New(fmt.Sprintln("Error in unmarshaling data", err))}
+}
@@ -0,0 +1,96 @@
+package dbifgen
+
+import (
+	"go/token"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// copyTree copies the directory tree src to dst
+func copyTree(t *testing.T, src string, dst string) {
+	err := filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return os.MkdirAll(filepath.Join(dst, rel), 0777)
+		}
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return ioutil.WriteFile(filepath.Join(dst, rel), data, 0666)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// generateTestModels copies the GOPATH tree in testdata/gopath to a temporary directory and
+// generates the code of its models/objects package there. It returns the GOPATH and the model.
+func generateTestModels(t *testing.T) (string, *Model) {
+	gopath := t.TempDir()
+	copyTree(t, filepath.Join("testdata", "gopath"), gopath)
+	objDir := filepath.Join(gopath, "src", "models", "objects")
+	model, err := LoadObjects(token.NewFileSet(), objDir)
+	if err != nil {
+		t.Fatal("Failed to load the test models:", err)
+	}
+	gen := NewGenerator(FileOutput{}, "objects", objDir, t.TempDir())
+	if err := gen.Generate(model); err != nil {
+		t.Fatal("Failed to generate the code of the test models:", err)
+	}
+	return gopath, model
+}
+
+// TestGeneratedCode runs the tests of testdata/gopath/src/models/objects against the code
+// generated for the objects of that package
+func TestGeneratedCode(t *testing.T) {
+	goCmd, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not found")
+	}
+	gopath, _ := generateTestModels(t)
+	args := []string{"test", "-count=1"}
+	if testing.Verbose() {
+		args = append(args, "-v")
+	}
+	cmd := exec.Command(goCmd, append(args, "models/objects")...)
+	cmd.Dir = gopath
+	cmd.Env = append(os.Environ(), "GO111MODULE=off", "GOFLAGS=", "GOPATH="+gopath)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("Tests of the generated code failed: %v\n%s", err, out)
+	}
+	if testing.Verbose() {
+		t.Logf("%s", out)
+	}
+}
+
+func TestMultiNameMembers(t *testing.T) {
+	_, model := generateTestModels(t)
+	obj := model.Objects["Edge"]
+	var names []string
+	positions := make(map[int]string)
+	for _, member := range obj.ConvertObjectMembersMapToOrderedSlice(model.Members["Edge"]) {
+		names = append(names, member.MemberName)
+		if other, exist := positions[member.Position]; exist {
+			t.Errorf("Members %s and %s have the same position %d", other, member.MemberName, member.Position)
+		}
+		positions[member.Position] = member.MemberName
+	}
+	if want := []string{"Name", "A", "B", "Descr"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("Members of Edge are %v, want %v", names, want)
+	}
+	if model.Members["Edge"]["B"].Description != "Ends of the edge" {
+		t.Errorf("Member B does not have the tag of A, B: %+v", model.Members["Edge"]["B"])
+	}
+}
@@ -0,0 +1,428 @@
+// Package dbifgen generates the redis db interface, serializers and schema files for the
+// flexswitch model objects. The dbif command is a thin wrapper around it.
+package dbifgen
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	MEMBER_JSON = "Members.json"
+)
+
+// Output is where the generator writes the files it produces. name is the path of the file
+// as computed from the directories the Generator was set up with.
+type Output interface {
+	WriteFile(name string, data []byte) error
+}
+
+// FileOutput writes generated files to the filesystem. Every file is replaced as a whole, see WriteFileAtomic.
+type FileOutput struct{}
+
+func (out FileOutput) WriteFile(name string, data []byte) error {
+	return WriteFileAtomic(name, data)
+}
+
+// WriteFileAtomic writes data to a temporary file next to name and renames it to name once it is
+// complete, so that a failure or a crash leaves either the old file or the new one, never a part
+// of it. The temporary file starts with a dot, which keeps it out of the go package if it is left
+// behind.
+func WriteFileAtomic(name string, data []byte) error {
+	tmpName := filepath.Join(filepath.Dir(name), "."+filepath.Base(name)+".tmp"+strconv.Itoa(os.Getpid()))
+	f, err := os.OpenFile(tmpName, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0666)
+	if err != nil {
+		return err
+	}
+	// The file keeps its mode, as it does when it is overwritten in place
+	if info, statErr := os.Stat(name); statErr == nil {
+		err = f.Chmod(info.Mode().Perm())
+	}
+	if err == nil {
+		_, err = f.Write(data)
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmpName, name)
+	}
+	if err != nil {
+		os.Remove(tmpName)
+	}
+	return err
+}
+
+// MemOutput keeps generated files in memory, keyed by file name
+type MemOutput map[string][]byte
+
+func (out MemOutput) WriteFile(name string, data []byte) error {
+	out[name] = append([]byte(nil), data...)
+	return nil
+}
+
+// Generator produces the db interface, serializer, Members.json and extschema files for one
+// package of model objects.
+type Generator struct {
+	Out Output
+	// Package clause of the generated go files
+	PackageName string
+	// Directory the generated go files are written to
+	SrcDir string
+	// Directory the Members.json and extschema files are written to
+	GenInfoDir string
+	// If set, the index of the objects is written to this file, see IndexFile
+	IndexFile string
+	// If set, generation goes on with the other objects when one fails and all failures are returned at the end
+	KeepGoing bool
+	// Number of objects whose db functions are generated at the same time, the number of CPUs if not set
+	Jobs int
+	// If set, the inputs of the files written are recorded in this manifest file and the files
+	// whose inputs are unchanged since the previous run are not generated again, see manifest.go
+	ManifestFile string
+	// If set, all files are generated whatever the manifest says
+	Force bool
+
+	files   []string
+	goFiles []string
+	// Manifests of the previous run and of this one, hashes of the inputs of the files to write
+	// and of the objects
+	prev      *Manifest
+	next      *Manifest
+	inputs    map[string]string
+	objHashes map[string]ObjectHashes
+	// Files left as they are by this run
+	skipped map[string]bool
+}
+
+func NewGenerator(out Output, packageName string, srcDir string, genInfoDir string) *Generator {
+	return &Generator{
+		Out:         out,
+		PackageName: packageName,
+		SrcDir:      srcDir,
+		GenInfoDir:  genInfoDir,
+	}
+}
+
+// GeneratedFiles returns all the files written so far
+func (gen *Generator) GeneratedFiles() []string {
+	return gen.files
+}
+
+// GeneratedGoFiles returns the go files written so far. These are the files listed in generatedGoFiles.txt
+func (gen *Generator) GeneratedGoFiles() []string {
+	return gen.goFiles
+}
+
+// writeFile records name and hands the data to the output. Go files are gofmt'ed first so that
+// they match what gencode.sh leaves on disk.
+func (gen *Generator) writeFile(name string, data []byte) error {
+	if strings.HasSuffix(name, ".go") {
+		formatted, err := formatGoFile(name, data)
+		if err != nil {
+			gen.recordFile(name)
+			return err
+		}
+		data = formatted
+	}
+	return gen.writeFormattedFile(name, data)
+}
+
+// writeFormattedFile is writeFile for go files that have been gofmt'ed already
+func (gen *Generator) writeFormattedFile(name string, data []byte) error {
+	gen.recordFile(name)
+	if err := gen.Out.WriteFile(name, data); err != nil {
+		return err
+	}
+	gen.wrote(name, data)
+	return nil
+}
+
+func (gen *Generator) recordFile(name string) {
+	gen.files = append(gen.files, name)
+	if strings.HasSuffix(name, ".go") {
+		gen.goFiles = append(gen.goFiles, name)
+	}
+}
+
+func formatGoFile(name string, data []byte) ([]byte, error) {
+	formatted, err := format.Source(data)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintln("Generated code for", name, "does not parse:", err))
+	}
+	return formatted, nil
+}
+
+// generatedFile is the content of a generated file or the failure to generate it
+type generatedFile struct {
+	data []byte
+	err  error
+}
+
+// dbFunctions returns the gofmt'ed gen_<object>dbif.go files of the objects with a DbFileName,
+// indexed like objs. The objects do not depend on each other, so up to gen.Jobs of them are
+// generated at the same time.
+func (gen *Generator) dbFunctions(objs []ObjectInfoJson, model *Model, objMap map[string]ObjectInfoJson) []generatedFile {
+	files := make([]generatedFile, len(objs))
+	jobs := gen.Jobs
+	if jobs <= 0 {
+		jobs = runtime.GOMAXPROCS(0)
+	}
+	running := make(chan bool, jobs)
+	var wg sync.WaitGroup
+	for i := range objs {
+		if objs[i].DbFileName == "" || gen.skipped[objs[i].DbFileName] {
+			continue
+		}
+		wg.Add(1)
+		running <- true
+		go func(i int) {
+			defer func() {
+				<-running
+				wg.Done()
+			}()
+			obj := objs[i]
+			data, err := obj.WriteDBFunctions(gen.PackageName, model.Members[obj.ObjName], objMap)
+			if err == nil {
+				data, err = formatGoFile(obj.DbFileName, data)
+			}
+			files[i] = generatedFile{data: data, err: err}
+		}(i)
+	}
+	wg.Wait()
+	return files
+}
+
+// Generate writes all the files derived from model. Objects whose members are unknown are skipped.
+// It stops at the first failure unless KeepGoing is set. The failures are returned as an ErrorList.
+// With a ManifestFile, the files whose inputs are unchanged are left as they are.
+func (gen *Generator) Generate(model *Model) error {
+	gen.loadManifest()
+	gen.skipped = make(map[string]bool)
+	err := gen.generate(model)
+	if saveErr := gen.saveManifest(); saveErr != nil {
+		// The next run generates everything again
+		logger.Warn("Failed to write the manifest", LOG_KEY_FILE, gen.ManifestFile, LOG_KEY_ERROR, saveErr)
+	}
+	return err
+}
+
+// skip reports whether the file name generated from inputs is unchanged and remembers the answer
+func (gen *Generator) skip(name string, inputs ...string) bool {
+	gen.skipped[name] = gen.unchanged(name, inputs...)
+	return gen.skipped[name]
+}
+
+func (gen *Generator) generate(model *Model) error {
+	var errs ErrorList
+	objMap := make(map[string]ObjectInfoJson, len(model.Objects))
+	for name, obj := range model.Objects {
+		objMap[name] = obj
+	}
+
+	parentChild := make(map[string][]string, 1)
+	childParent := make(map[string]string, 1)
+	// Objects with known members, in name order
+	var objs []ObjectInfoJson
+	for _, name := range sortedObjectNames(objMap) {
+		obj := objMap[name]
+		obj.ObjName = name
+		membersInfo, exist := model.Members[name]
+		if !exist {
+			continue
+		}
+		for _, val := range obj.ConvertObjectMembersMapToOrderedSlice(membersInfo) {
+			if val.UsesStateDB == true {
+				obj.UsesStateDB = true
+			}
+			if val.AutoCreate == true {
+				obj.AutoCreate = true
+			}
+			if val.AutoDiscover == true {
+				obj.AutoDiscover = true
+			}
+			if val.IsParentSet && !model.Actions {
+				// Set parent to true when auto create is set
+				// Temporarily store parent child into a map...
+				pEntry := parentChild[val.Parent]
+				pEntry = append(pEntry, name)
+				parentChild[val.Parent] = pEntry
+				childParent[name] = val.Parent
+			}
+		}
+		// The flags derived from the members go into the index
+		entry := objMap[name]
+		entry.UsesStateDB, entry.AutoCreate, entry.AutoDiscover = obj.UsesStateDB, obj.AutoCreate, obj.AutoDiscover
+		objMap[name] = entry
+		if strings.ContainsAny(obj.Access, "rw") {
+			obj.DbFileName = filepath.Join(gen.SrcDir, "gen_"+name+"dbif.go")
+		}
+		objs = append(objs, obj)
+	}
+	addLinkedObjects(parentChild, childParent, objMap)
+	gen.setObjectHashes(model, objMap)
+
+	for _, obj := range objs {
+		gen.skip(gen.membersFile(obj.ObjName), gen.objectsInputs(obj.ObjName)...)
+		if obj.DbFileName != "" {
+			// The db functions of a state object depend on its config object
+			inputs := gen.objectsInputs(obj.ObjName)
+			if configObjName := strings.TrimSuffix(obj.ObjName, "State"); configObjName != obj.ObjName {
+				inputs = append(inputs, gen.objectsInputs(configObjName)...)
+			}
+			gen.skip(obj.DbFileName, inputs...)
+		}
+	}
+
+	// The files are written in name order, whatever order they are generated in
+	dbFiles := gen.dbFunctions(objs, model, objMap)
+	for i, obj := range objs {
+		if !gen.skipped[gen.membersFile(obj.ObjName)] {
+			logger.Debug("Writing members", LOG_KEY_PHASE, PHASE_MEMBERS, LOG_KEY_OBJECT, obj.ObjName, LOG_KEY_OWNER, obj.Owner)
+			err := gen.writeMembersInfo(obj.ObjName, model.Members[obj.ObjName])
+			if err = gen.fail(&errs, err, obj.ObjName, PHASE_MEMBERS); err != nil {
+				return err
+			}
+		}
+		if obj.DbFileName != "" && !gen.skipped[obj.DbFileName] {
+			logger.Debug("Writing db functions", LOG_KEY_PHASE, PHASE_DBIF, LOG_KEY_OBJECT, obj.ObjName, LOG_KEY_OWNER, obj.Owner,
+				LOG_KEY_FILE, obj.DbFileName)
+			err := dbFiles[i].err
+			if err == nil {
+				err = gen.writeFormattedFile(obj.DbFileName, dbFiles[i].data)
+			}
+			if err = gen.fail(&errs, err, obj.ObjName, PHASE_DBIF); err != nil {
+				return err
+			}
+		}
+	}
+
+	if !gen.skip(filepath.Join(gen.SrcDir, COMMON_FILE), strconv.FormatBool(model.Actions)) {
+		err := gen.writeCommonFile(model)
+		if err = gen.fail(&errs, err, "", PHASE_DBIF); err != nil {
+			return err
+		}
+	}
+
+	if gen.IndexFile != "" {
+		lines, err := json.MarshalIndent(objMap, "", " ")
+		if err == nil && !gen.skip(gen.IndexFile, string(lines)) {
+			logger.Debug("Writing object index", LOG_KEY_PHASE, PHASE_INDEX, LOG_KEY_FILE, gen.IndexFile)
+			err = gen.writeFile(gen.IndexFile, lines)
+		}
+		if err = gen.fail(&errs, err, "", PHASE_INDEX); err != nil {
+			return err
+		}
+	}
+
+	objectsByOwner := make(map[string][]ObjectInfoJson, 1)
+	for _, name := range sortedObjectNames(objMap) {
+		obj := objMap[name]
+		obj.ObjName = name
+		objectsByOwner[obj.Owner] = append(objectsByOwner[obj.Owner], obj)
+	}
+
+	if err := gen.generateSerializers(model, objectsByOwner, &errs); err != nil {
+		return err
+	}
+	if err := gen.genJsonSchema(model, objectsByOwner, &errs); err != nil {
+		return err
+	}
+	return errs.Err()
+}
+
+// fail adds err, if any, to errs. It returns the failures collected so far when the generation
+// has to stop, that is unless KeepGoing is set.
+func (gen *Generator) fail(errs *ErrorList, err error, object string, phase string) error {
+	if err == nil {
+		return nil
+	}
+	errs.Add(err, object, phase)
+	if gen.KeepGoing {
+		return nil
+	}
+	return errs.Err()
+}
+
+// writeMembersInfo writes the skeleton of the structure in json.
+// This would help later python scripts to understand the structure
+func (gen *Generator) writeMembersInfo(objName string, objMembers map[string]ObjectMembersInfo) error {
+	lines, err := json.MarshalIndent(objMembers, "", " ")
+	if err != nil {
+		return errors.New(fmt.Sprintln("Error in converting to Json", err))
+	}
+	return gen.writeFile(gen.membersFile(objName), lines)
+}
+
+func (gen *Generator) membersFile(objName string) string {
+	return filepath.Join(gen.GenInfoDir, objName+MEMBER_JSON)
+}
+
+// addLinkedObjects sets the parent/child links derived from the PARENT tags of the members
+func addLinkedObjects(parentChild map[string][]string, childParent map[string]string,
+	objMap map[string]ObjectInfoJson) {
+	// The links are derived from the models on every run. Links found in a config file that was
+	// rewritten by older versions of the generator are dropped.
+	for key, entry := range objMap {
+		entry.LinkedObjects = nil
+		entry.Parent = ""
+		objMap[key] = entry
+	}
+	for key, value := range parentChild {
+		entry, exists := objMap[key]
+		if exists {
+			sort.Strings(value)
+			entry.LinkedObjects = value
+			objMap[key] = entry
+		}
+	}
+
+	for key, value := range childParent {
+		entry, exists := objMap[key]
+		if exists {
+			entry.Parent = value
+			objMap[key] = entry
+		}
+	}
+}
+
+// Generated output must not depend on map iteration order, so maps are always walked in sorted order
+
+func sortedObjectNames(objMap map[string]ObjectInfoJson) []string {
+	names := make([]string, 0, len(objMap))
+	for name := range objMap {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func sortedOwners(objectsByOwner map[string][]ObjectInfoJson) []string {
+	owners := make([]string, 0, len(objectsByOwner))
+	for owner := range objectsByOwner {
+		owners = append(owners, owner)
+	}
+	sort.Strings(owners)
+	return owners
+}
+
+func sortedSrcFiles(srcsMap map[string]RawObjSrcInfo) []string {
+	srcFiles := make([]string, 0, len(srcsMap))
+	for srcFile := range srcsMap {
+		srcFiles = append(srcFiles, srcFile)
+	}
+	sort.Strings(srcFiles)
+	return srcFiles
+}
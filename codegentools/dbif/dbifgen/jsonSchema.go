@@ -0,0 +1,182 @@
+package dbifgen
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+)
+
+type KeyInfo struct {
+	VarType    string       `json:"type"`
+	RefTable   string       `json:"refTable,omitempty"`
+	MinInteger json.Number  `json:"minInteger,omitempty"`
+	MaxInteger json.Number  `json:"maxInteger,omitempty"`
+	Ranges     []ValueRange `json:"ranges,omitempty"`
+	//Min      int    `json:"minLength, omitempty"`
+	//Max      int    `json:"maxLength, omitempty"`
+}
+
+type TypeInfo struct {
+	Key KeyInfo `json:"key"`
+	// Type of the values of a map member
+	Value *KeyInfo `json:"value,omitempty"`
+}
+
+type ColumnInfo struct {
+	Category string   `json:"category"`
+	Type     TypeInfo `json:"type"`
+}
+
+type TableInfo struct {
+	Columns map[string]ColumnInfo `json:"columns"`
+	Indexes [][]string            `json:"indexes,omitempty"`
+	IsRoot  bool                  `json:"isRoot,omitempty"`
+}
+
+type SchemaInfo struct {
+	Name    string               `json:"name"`
+	Version string               `json:"version"`
+	Tables  map[string]TableInfo `json:"tables"`
+}
+
+func createSchema(objMap map[string]ObjectMembersInfo, objConfig ObjectInfoJson) TableInfo {
+	var ovsColumns map[string]ColumnInfo
+	var table TableInfo
+	var indexes []string
+
+	ovsColumns = make(map[string]ColumnInfo, len(objMap))
+
+	// Walk the members in declaration order so that the order of a composite index is stable
+	for _, obj := range objConfig.ConvertObjectMembersMapToOrderedSlice(objMap) {
+		name := obj.MemberName
+		info := ColumnInfo{}
+
+		switch objConfig.Access {
+		case "r":
+			info.Category = "status"
+		case "w":
+			info.Category = "configuration"
+		case "":
+			// This is special case for objects that are referenced
+			info.Category = "configuration"
+		}
+
+		if obj.BaseType != "" {
+			// Named types are described by the basic type they are of
+			info.Type.Key = namedKeyInfo(obj.BaseType)
+		} else if obj.GoType != nil {
+			info.Type = nestedTypeInfo(obj.GoType)
+		} else {
+			info.Type.Key = namedKeyInfo(obj.VarType)
+		}
+		if obj.IsKey {
+			indexes = append(indexes, name)
+			table.IsRoot = true
+		}
+		//@TODO: jgheewala add support for min, max, minLength, maxLength
+		if len(obj.Ranges) > 0 {
+			info.Type.Key.MinInteger, info.Type.Key.MaxInteger = rangesSpan(obj.Ranges)
+			// The overall bounds describe a single interval, anything else needs the list
+			if len(obj.Ranges) > 1 {
+				info.Type.Key.Ranges = obj.Ranges
+			}
+		}
+		ovsColumns[name] = info
+	}
+
+	if len(indexes) > 0 {
+		table.Indexes = append(table.Indexes, indexes)
+	}
+	table.Columns = ovsColumns
+
+	return table
+
+}
+
+// namedKeyInfo returns the schema type of a member of the named type varType
+func namedKeyInfo(varType string) KeyInfo {
+	var key KeyInfo
+	switch varType {
+	case "uint8", "uint16", "uint32", "uint64", "int8", "int16", "int32":
+		key.VarType = "integer"
+	case "bool":
+		key.VarType = "boolean"
+	case "string":
+		key.VarType = "string"
+	default:
+		key.VarType = "uuid"
+		key.RefTable = varType
+	}
+	return key
+}
+
+// nestedTypeInfo returns the schema type of a member of a nested type. Pointers, slices and
+// arrays are described by their element type and maps by their key and value types. Structs are
+// kept in the db as json text, so they are strings.
+func nestedTypeInfo(typ *MemberType) TypeInfo {
+	var info TypeInfo
+	elemKey := func(t *MemberType) KeyInfo {
+		for t.Kind == TYPE_POINTER || t.Kind == TYPE_SLICE || t.Kind == TYPE_ARRAY {
+			t = t.Elem
+		}
+		if t.Kind == TYPE_NAMED {
+			return namedKeyInfo(t.String())
+		}
+		// Structs and maps within other types are json text as well
+		return KeyInfo{VarType: "string"}
+	}
+	for typ.Kind == TYPE_POINTER || typ.Kind == TYPE_SLICE || typ.Kind == TYPE_ARRAY {
+		typ = typ.Elem
+	}
+	if typ.Kind == TYPE_MAP {
+		value := elemKey(typ.Elem)
+		info.Key = elemKey(typ.Key)
+		info.Value = &value
+	} else {
+		info.Key = elemKey(typ)
+	}
+	return info
+}
+
+func (gen *Generator) writeJson(extSchemaFile string, jsonSchema SchemaInfo) error {
+	lines, err := json.MarshalIndent(jsonSchema, "", "   ")
+	if err != nil {
+		return errors.New(fmt.Sprintln("Error in converting to json", err))
+	}
+	return gen.writeFile(extSchemaFile, lines)
+}
+
+// genJsonSchema writes one extschema file per owner describing the tables of its objects
+func (gen *Generator) genJsonSchema(model *Model, objectsByOwner map[string][]ObjectInfoJson, errs *ErrorList) error {
+	for _, owner := range sortedOwners(objectsByOwner) {
+		objList := objectsByOwner[owner]
+		extSchemaFile := filepath.Join(gen.GenInfoDir, owner+".extschema")
+		if gen.skip(extSchemaFile, gen.ownerInputs(model, objList)...) {
+			continue
+		}
+		var jsonSchema SchemaInfo
+		ovsTables := make(map[string]TableInfo)
+		jsonSchema.Name = owner
+		jsonSchema.Version = "0.0.1"
+		for _, obj := range objList {
+			if obj.Access == "x" {
+				continue
+			}
+			objMap, exist := model.Members[obj.ObjName]
+			if !exist {
+				logger.Warn("Members of object are not known", LOG_KEY_PHASE, PHASE_SCHEMA, LOG_KEY_OBJECT, obj.ObjName, LOG_KEY_OWNER, owner)
+				continue
+			}
+			table := createSchema(objMap, obj)
+			ovsTables[obj.ObjName] = table
+		}
+		jsonSchema.Tables = ovsTables
+		logger.Debug("Writing schema", LOG_KEY_PHASE, PHASE_SCHEMA, LOG_KEY_OWNER, owner, LOG_KEY_FILE, extSchemaFile)
+		err := gen.writeJson(extSchemaFile, jsonSchema)
+		if err = gen.fail(errs, err, "", PHASE_SCHEMA); err != nil {
+			return err
+		}
+	}
+	return nil
+}
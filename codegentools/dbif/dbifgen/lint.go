@@ -0,0 +1,262 @@
+package dbifgen
+
+import (
+	"go/token"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	LINT_ERROR   = "error"
+	LINT_WARNING = "warning"
+)
+
+// Tags understood by the generator. ACCESS, MULTIPLICITY and the like are object level tags that
+// are carried by one of the members of hand coded objects. CATEGORY and LEN are emitted by pybind.
+var knownTags = map[string]bool{
+	"SNAPROUTE":        true,
+	"DESCRIPTION":      true,
+	"SELECTION":        true,
+	"DEFAULT":          true,
+	"ACCELERATED":      true,
+	"MIN":              true,
+	"MAX":              true,
+	"RANGE":            true,
+	"STRLEN":           true,
+	"LEN":              true,
+	"QPARAM":           true,
+	"USESTATEDB":       true,
+	"AUTOCREATE":       true,
+	"AUTODISCOVER":     true,
+	"PARENT":           true,
+	"UNIT":             true,
+	"ACCESS":           true,
+	"MULTIPLICITY":     true,
+	"CATEGORY":         true,
+	"LENIENTUNMARSHAL": true,
+}
+
+var validAccess = map[string]bool{
+	"r":  true,
+	"w":  true,
+	"rw": true,
+	"x":  true,
+}
+
+// Selections of integer members are written by pybind as label(value), e.g. up(1)/down(2)
+var intSelection = regexp.MustCompile(`^[^()]*\((-?[0-9]+)\)$|^(-?[0-9]+)$`)
+
+// LintFinding is a problem found in the model sources
+type LintFinding struct {
+	Pos      token.Position
+	Object   string
+	Member   string
+	Severity string
+	Msg      string
+}
+
+func (finding LintFinding) String() string {
+	var prefix string
+	if finding.Pos.Filename != "" {
+		prefix = finding.Pos.Filename + ":"
+		if finding.Pos.Line > 0 {
+			prefix += strconv.Itoa(finding.Pos.Line) + ":"
+		}
+		prefix += " "
+	}
+	name := finding.Object
+	if finding.Member != "" {
+		name += "." + finding.Member
+	}
+	if name != "" {
+		prefix += name + ": "
+	}
+	return prefix + finding.Severity + ": " + finding.Msg
+}
+
+// LintErrors turns the errors returned by LoadObjects/LoadActions into findings
+func LintErrors(err error) []LintFinding {
+	var errs ErrorList
+	errs.Add(err, "", PHASE_LOAD)
+	var findings []LintFinding
+	for _, e := range errs {
+		findings = append(findings, LintFinding{
+			Pos:      e.Pos,
+			Object:   e.Object,
+			Severity: LINT_ERROR,
+			Msg:      strings.TrimSpace(e.Err.Error()),
+		})
+	}
+	return findings
+}
+
+// Lint checks the model for mistakes the generator does not catch itself. The findings are
+// sorted by position.
+func Lint(fset *token.FileSet, model *Model) []LintFinding {
+	var findings []LintFinding
+	add := func(pos token.Pos, object string, member string, severity string, msg string) {
+		findings = append(findings, LintFinding{
+			Pos:      fset.Position(pos),
+			Object:   object,
+			Member:   member,
+			Severity: severity,
+			Msg:      msg,
+		})
+	}
+
+	owners := make(map[string]bool)
+	for _, srcInfo := range model.Sources {
+		owners[srcInfo.Owner] = true
+	}
+	missingOwners := make(map[string]bool)
+
+	for _, name := range sortedObjectNames(model.Objects) {
+		obj := model.Objects[name]
+		// Hand coded files also hold helper structures, which are not objects and have no access
+		_, handCoded := model.Sources[obj.SrcFile]
+		if !validAccess[obj.Access] && !(handCoded && obj.Access == "") {
+			add(obj.Pos, name, "", LINT_ERROR, "Invalid access "+strconv.Quote(obj.Access)+", expected r, w, rw or x")
+		}
+		if !owners[obj.Owner] && !missingOwners[obj.Owner] {
+			missingOwners[obj.Owner] = true
+			add(obj.Pos, name, "", LINT_WARNING, "Owner "+strconv.Quote(obj.Owner)+" is not listed in "+srcInfoFile(model))
+		}
+
+		members, exist := model.Members[name]
+		if !exist {
+			continue
+		}
+		hasKey := false
+		for _, info := range obj.ConvertObjectMembersMapToOrderedSlice(members) {
+			if info.IsKey {
+				hasKey = true
+			}
+			for _, msg := range lintMember(info, model) {
+				add(info.Pos, name, info.MemberName, msg.Severity, msg.Msg)
+			}
+		}
+		if !model.Actions && strings.Contains(obj.Access, "w") && !hasKey {
+			add(obj.Pos, name, "", LINT_ERROR, "Config object has no SNAPROUTE key member")
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].Pos.Filename != findings[j].Pos.Filename {
+			return findings[i].Pos.Filename < findings[j].Pos.Filename
+		}
+		return findings[i].Pos.Line < findings[j].Pos.Line
+	})
+	return findings
+}
+
+func srcInfoFile(model *Model) string {
+	if model.Actions {
+		return ACTION_SRC_INFO_FILE
+	}
+	return OBJECT_SRC_INFO_FILE
+}
+
+// lintMember checks the tags of one member. Only Severity and Msg of the findings are set.
+func lintMember(info ObjectMemberAndInfo, model *Model) []LintFinding {
+	var findings []LintFinding
+	report := func(severity string, msg string) {
+		findings = append(findings, LintFinding{Severity: severity, Msg: msg})
+	}
+
+	// Tags that do not parse have already been reported when the model was loaded
+	tag, _ := parseTagLiteral(info.Tag)
+	tags := make(map[string]bool)
+	for _, name := range tag.Names() {
+		tags[name] = true
+		if !knownTags[name] {
+			report(LINT_WARNING, "Unknown tag "+name)
+		}
+	}
+
+	if info.IsDefaultSet && !info.IsArray && info.GoType != nil && info.BaseType == "" {
+		report(LINT_WARNING, "DEFAULT is ignored for members of type "+info.VarType)
+	} else if info.IsDefaultSet && !info.IsArray {
+		if !defaultParses(info.baseType(), info.DefaultVal) {
+			report(LINT_ERROR, "Default "+strconv.Quote(info.DefaultVal)+" is not a valid "+info.VarType)
+		}
+		if len(info.Selections) > 0 {
+			selected := false
+			for _, selection := range info.Selections {
+				// Integer selections are written as label(value) and the default is the value
+				match := intSelection.FindStringSubmatch(selection)
+				if selection == info.DefaultVal || (match != nil && match[1] == info.DefaultVal) {
+					selected = true
+				}
+			}
+			if !selected {
+				report(LINT_ERROR, "Default "+strconv.Quote(info.DefaultVal)+" is not one of the selections "+strings.Join(info.Selections, "/"))
+			}
+		}
+	}
+	if tags["MIN"] && tags["MAX"] && info.Min > info.Max {
+		report(LINT_ERROR, "MIN "+strconv.Itoa(info.Min)+" is greater than MAX "+strconv.Itoa(info.Max))
+	}
+	if len(info.Ranges) > 0 {
+		if !isIntegerType(info.baseType()) {
+			report(LINT_ERROR, "RANGE is only supported for integer members, not "+info.VarType)
+		} else {
+			for _, r := range info.Ranges {
+				for _, bound := range []string{string(r.Min), string(r.Max)} {
+					if bound != "" && !defaultParses(info.baseType(), bound) {
+						report(LINT_ERROR, "Range bound "+bound+" does not fit "+info.VarType)
+					}
+				}
+			}
+		}
+	}
+	if info.QueryParam != "" && !strings.EqualFold(info.QueryParam, "optional") && !strings.EqualFold(info.QueryParam, "mandatory") {
+		report(LINT_WARNING, "QPARAM "+strconv.Quote(info.QueryParam)+" is neither optional nor mandatory")
+	}
+	if info.IsParentSet {
+		if _, exist := model.Objects[info.Parent]; !exist {
+			report(LINT_ERROR, "Parent "+strconv.Quote(info.Parent)+" is not a known object")
+		}
+	}
+	return findings
+}
+
+// defaultParses reports whether value is a valid literal of the basic go type varType. Values of
+// other types can not be checked and are accepted.
+func defaultParses(varType string, value string) bool {
+	var err error
+	switch varType {
+	case "bool":
+		_, err = strconv.ParseBool(value)
+	case "int", "int64":
+		_, err = strconv.ParseInt(value, 0, 64)
+	case "int8":
+		_, err = strconv.ParseInt(value, 0, 8)
+	case "int16":
+		_, err = strconv.ParseInt(value, 0, 16)
+	case "int32":
+		_, err = strconv.ParseInt(value, 0, 32)
+	case "uint", "uint64":
+		_, err = strconv.ParseUint(value, 0, 64)
+	case "uint8", "byte":
+		_, err = strconv.ParseUint(value, 0, 8)
+	case "uint16":
+		_, err = strconv.ParseUint(value, 0, 16)
+	case "uint32":
+		_, err = strconv.ParseUint(value, 0, 32)
+	case "float32":
+		_, err = strconv.ParseFloat(value, 32)
+	case "float64":
+		_, err = strconv.ParseFloat(value, 64)
+	}
+	return err == nil
+}
+
+func isIntegerType(varType string) bool {
+	switch varType {
+	case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64", "byte":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,35 @@
+package dbifgen
+
+import (
+	"log/slog"
+)
+
+// Keys of the structured fields attached to log records
+const (
+	LOG_KEY_PHASE  = "phase"
+	LOG_KEY_OBJECT = "object"
+	LOG_KEY_OWNER  = "owner"
+	LOG_KEY_FILE   = "file"
+	LOG_KEY_ERROR  = "err"
+)
+
+// Phases of a generator run, used as the value of the phase field
+const (
+	PHASE_LOAD       = "load"
+	PHASE_MEMBERS    = "members"
+	PHASE_DBIF       = "dbif"
+	PHASE_INDEX      = "index"
+	PHASE_SERIALIZER = "serializer"
+	PHASE_SCHEMA     = "schema"
+)
+
+// The package logs nothing until SetLogger is called
+var logger = slog.New(slog.DiscardHandler)
+
+// SetLogger sets the logger the generator reports its progress to. A nil logger turns logging off.
+func SetLogger(l *slog.Logger) {
+	if l == nil {
+		l = slog.New(slog.DiscardHandler)
+	}
+	logger = l
+}
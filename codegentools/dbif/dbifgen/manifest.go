@@ -0,0 +1,190 @@
+package dbifgen
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strconv"
+)
+
+const (
+	// The manifest of a package of objects is kept in the genInfo directory as <package>GenManifest.json
+	MANIFEST_FILE_SUFFIX = "GenManifest.json"
+	// Version of the generated code. It has to be bumped whenever a change to the generator changes
+	// the files it writes for the same models, else files generated by the previous version are kept.
+	GENERATOR_VERSION = "1"
+)
+
+// Manifest records what the files written by a run were generated from. A file whose inputs
+// hash the same on the next run, and that has not been changed on disk since, is not generated
+// again, so that its modification time does not force a rebuild of the package.
+type Manifest struct {
+	// GENERATOR_VERSION of the generator that wrote the files. A manifest of another version is not used.
+	Version string `json:"version"`
+	// Hashes of the inputs of every object, by object name
+	Objects map[string]ObjectHashes `json:"objects"`
+	// Written files, by path
+	Files map[string]FileHashes `json:"files"`
+}
+
+// ObjectHashes are the hashes of what an object is generated from
+type ObjectHashes struct {
+	// Members read from the go structure of the object, including embedded structures
+	Source string `json:"source"`
+	// Entry of the object in the index file: its config entry with the flags and links derived from the models
+	Config string `json:"config"`
+}
+
+// FileHashes are the hash of the inputs of a file and the checksum of the data written
+type FileHashes struct {
+	Inputs string `json:"inputs"`
+	Sum    string `json:"sum"`
+}
+
+func newManifest(version string) *Manifest {
+	return &Manifest{
+		Version: version,
+		Objects: make(map[string]ObjectHashes),
+		Files:   make(map[string]FileHashes),
+	}
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// hashOf returns the hash of a list of strings
+func hashOf(parts ...string) string {
+	h := sha256.New()
+	for _, part := range parts {
+		h.Write([]byte(strconv.Quote(part)))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// jsonHash returns the hash of the json encoding of v, whose maps are encoded in key order
+func jsonHash(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	return checksum(data)
+}
+
+// loadManifest reads the manifest of the previous run. Without ManifestFile, or if Force is set,
+// every file is generated and no manifest is kept.
+func (gen *Generator) loadManifest() {
+	gen.prev, gen.next, gen.inputs = nil, nil, make(map[string]string)
+	if gen.ManifestFile == "" {
+		return
+	}
+	gen.next = newManifest(GENERATOR_VERSION)
+	if gen.Force {
+		return
+	}
+	data, err := ioutil.ReadFile(gen.ManifestFile)
+	if err != nil {
+		return
+	}
+	prev := newManifest("")
+	if err := json.Unmarshal(data, prev); err != nil {
+		logger.Warn("Manifest does not parse, generating all files", LOG_KEY_FILE, gen.ManifestFile, LOG_KEY_ERROR, err)
+		return
+	}
+	if prev.Version != GENERATOR_VERSION {
+		logger.Debug("Manifest is of another generator version, generating all files", LOG_KEY_FILE, gen.ManifestFile)
+		return
+	}
+	gen.prev = prev
+}
+
+// saveManifest writes the manifest of this run. Files that failed are not in it, so they are
+// generated again on the next run.
+func (gen *Generator) saveManifest() error {
+	if gen.next == nil {
+		return nil
+	}
+	data, err := json.MarshalIndent(gen.next, "", " ")
+	if err != nil {
+		return err
+	}
+	return gen.Out.WriteFile(gen.ManifestFile, data)
+}
+
+// setObjectHashes computes the hashes of the index entry of every object of objMap and of the
+// members read for it
+func (gen *Generator) setObjectHashes(model *Model, objMap map[string]ObjectInfoJson) {
+	gen.objHashes = make(map[string]ObjectHashes, len(objMap))
+	for name, obj := range objMap {
+		hashes := ObjectHashes{Config: jsonHash(obj)}
+		if members, exist := model.Members[name]; exist {
+			hashes.Source = jsonHash(members)
+		}
+		gen.objHashes[name] = hashes
+		if gen.next != nil {
+			gen.next.Objects[name] = hashes
+		}
+	}
+}
+
+// objectsInputs returns the parts of the inputs of a file generated from the objects names
+func (gen *Generator) objectsInputs(names ...string) []string {
+	sorted := append([]string(nil), names...)
+	sort.Strings(sorted)
+	var parts []string
+	for _, name := range sorted {
+		hashes := gen.objHashes[name]
+		parts = append(parts, name, hashes.Source, hashes.Config)
+	}
+	return parts
+}
+
+// ownerInputs returns the parts of the inputs of a file generated from the objects of an owner
+func (gen *Generator) ownerInputs(model *Model, objList []ObjectInfoJson) []string {
+	names := make([]string, 0, len(objList))
+	for _, obj := range objList {
+		names = append(names, obj.ObjName)
+	}
+	return append([]string{strconv.FormatBool(model.Actions)}, gen.objectsInputs(names...)...)
+}
+
+// unchanged reports whether the file name, generated from inputs, is the same as the one written
+// by the previous run. The file then counts as generated but is not written again. Otherwise
+// the inputs are kept for the manifest entry of the file once it is written.
+func (gen *Generator) unchanged(name string, inputs ...string) bool {
+	if gen.next == nil {
+		return false
+	}
+	hash := hashOf(append([]string{gen.next.Version, gen.PackageName, filepath.Base(name)}, inputs...)...)
+	gen.inputs[name] = hash
+	if gen.prev == nil {
+		return false
+	}
+	entry, exist := gen.prev.Files[name]
+	if !exist || entry.Inputs != hash {
+		return false
+	}
+	// A file changed or removed by hand is written again
+	data, err := ioutil.ReadFile(name)
+	if err != nil || checksum(data) != entry.Sum {
+		return false
+	}
+	logger.Debug("Skipping unchanged file", LOG_KEY_FILE, name)
+	gen.recordFile(name)
+	gen.next.Files[name] = entry
+	return true
+}
+
+// wrote adds the file name written with data to the manifest if its inputs are known
+func (gen *Generator) wrote(name string, data []byte) {
+	if gen.next == nil {
+		return
+	}
+	if hash, exist := gen.inputs[name]; exist {
+		gen.next.Files[name] = FileHashes{Inputs: hash, Sum: checksum(data)}
+	}
+}
@@ -0,0 +1,704 @@
+package dbifgen
+
+import (
+	"bytes"
+	"strconv"
+)
+
+// Code applying RFC 6902 JSON Patch and RFC 7386 JSON Merge Patch documents to config objects.
+// It is part of the common file of the objects package.
+var commonPatchCode = `
+// JSONPatchOp is one operation of an RFC 6902 JSON Patch document
+type JSONPatchOp struct {
+	Op    string          ` + "`json:\"op\"`" + `
+	Path  string          ` + "`json:\"path\"`" + `
+	From  string          ` + "`json:\"from,omitempty\"`" + `
+	Value json.RawMessage ` + "`json:\"value,omitempty\"`" + `
+}
+
+// PatchError is a patch operation that can not be applied to an object. Index is the position
+// of the operation in the patch, or -1 if the patch document or the patched object is invalid.
+// A patch that fails is not applied at all.
+type PatchError struct {
+	Object string
+	Index  int
+	Op     string
+	Path   string
+	Msg    string
+}
+
+func (e *PatchError) Error() string {
+	if e.Index < 0 {
+		return "Failed to patch " + e.Object + ": " + e.Msg
+	}
+	return fmt.Sprintf("Failed to patch %s, operation %d (%s %s): %s", e.Object, e.Index, e.Op, e.Path, e.Msg)
+}
+
+// parseJSONPatch decodes an RFC 6902 JSON Patch document
+func parseJSONPatch(objName string, patch []byte) ([]JSONPatchOp, error) {
+	var ops []JSONPatchOp
+	if err := json.Unmarshal(patch, &ops); err != nil {
+		return nil, &PatchError{Object: objName, Index: -1, Msg: "invalid patch document: " + err.Error()}
+	}
+	return ops, nil
+}
+
+// patchOpsFromPatchOpInfo converts operations given as PatchOpInfo, whose Value holds the json
+// value of the operation. The from pointer of move and copy is taken from the From member of
+// PatchOpInfo. PatchOpInfo need not have one, move and copy are then only supported by
+// ApplyJSONPatch.
+func patchOpsFromPatchOpInfo(objName string, patchOpInfoSlice []PatchOpInfo) ([]JSONPatchOp, error) {
+	ops := make([]JSONPatchOp, 0, len(patchOpInfoSlice))
+	for idx, info := range patchOpInfoSlice {
+		from, hasFrom := patchOpInfoFrom(info)
+		if (info.Op == "move" || info.Op == "copy") && !hasFrom {
+			return nil, &PatchError{Object: objName, Index: idx, Op: info.Op, Path: info.Path, Msg: "not supported, PatchOpInfo has no From member, use ApplyJSONPatch"}
+		}
+		op := JSONPatchOp{Op: info.Op, Path: info.Path, From: from}
+		if info.Value != "" {
+			op.Value = json.RawMessage(info.Value)
+		}
+		ops = append(ops, op)
+	}
+	return ops, nil
+}
+
+// patchOpInfoFrom returns the From member of info. The second result is false if PatchOpInfo
+// has no such string member.
+func patchOpInfoFrom(info PatchOpInfo) (string, bool) {
+	from := reflect.ValueOf(info).FieldByName("From")
+	if !from.IsValid() || from.Kind() != reflect.String {
+		return "", false
+	}
+	return from.String(), true
+}
+
+// applyJSONPatch applies ops to the json form of *src and decodes the result into *dst. src,
+// defaults and dst point to objects of type objName, whose attribute names are names and whose
+// key attributes are keys. It returns the attributes the operations modify.
+//
+// Paths are JSON Pointers into the object, such as /Members/3 or /Members/- . An attribute can
+// not be missing from an object, so removing one resets it to its value in *defaults. The value
+// of a key attribute can not be changed. For compatibility with earlier clients, a path that
+// does not start with '/' is the name of an attribute: add appends the elements of the array
+// value to the attribute, remove removes them from it and replace sets the attribute to the value.
+func applyJSONPatch(objName string, names []string, keys []string, src interface{}, defaults interface{}, dst interface{}, ops []JSONPatchOp) (AttrSet, error) {
+	attrs := newAttrSet(names)
+	doc, err := jsonDocument(src)
+	if err != nil {
+		return attrs, &PatchError{Object: objName, Index: -1, Msg: err.Error()}
+	}
+	defaultDoc, err := jsonDocument(defaults)
+	if err != nil {
+		return attrs, &PatchError{Object: objName, Index: -1, Msg: err.Error()}
+	}
+	// The operations change doc in place
+	orig := copyJSONValue(doc)
+	for idx, op := range ops {
+		var touched []string
+		if op.Path != "" && !strings.HasPrefix(op.Path, "/") {
+			touched, err = applyAttrPatchOp(doc, names, op)
+		} else {
+			doc, touched, err = applyPatchOp(doc, names, defaultDoc, op)
+		}
+		if err == nil {
+			err = checkKeys(keys, orig, doc)
+		}
+		if err != nil {
+			return newAttrSet(names), &PatchError{Object: objName, Index: idx, Op: op.Op, Path: op.Path, Msg: err.Error()}
+		}
+		for _, name := range touched {
+			attrs.addName(name)
+		}
+	}
+	data, err := json.Marshal(doc)
+	if err == nil {
+		err = decodeObject(data, dst)
+	}
+	if err != nil {
+		return newAttrSet(names), &PatchError{Object: objName, Index: -1, Msg: "patched object is invalid: " + err.Error()}
+	}
+	return attrs, nil
+}
+
+// jsonDocument returns the json form of *obj decoded into generic values. Numbers are kept as
+// json.Number so that 64 bit integers do not lose precision. Nil slices and maps become empty
+// arrays and objects so that elements can be added to them.
+func jsonDocument(obj interface{}) (interface{}, error) {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := decodeJSONValue(data)
+	if err != nil {
+		return nil, err
+	}
+	if members, ok := doc.(map[string]interface{}); ok {
+		objVal := reflect.ValueOf(obj).Elem()
+		for name, value := range members {
+			if value != nil {
+				continue
+			}
+			switch objVal.FieldByName(name).Kind() {
+			case reflect.Slice:
+				members[name] = []interface{}{}
+			case reflect.Map:
+				members[name] = map[string]interface{}{}
+			}
+		}
+	}
+	return doc, nil
+}
+
+// decodeObject decodes the json form of an object into a new object and stores it in *dst, so
+// that members missing from data are zero and *dst is not modified if data is invalid
+func decodeObject(data []byte, dst interface{}) error {
+	obj := reflect.New(reflect.TypeOf(dst).Elem())
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(obj.Interface()); err != nil {
+		return err
+	}
+	reflect.ValueOf(dst).Elem().Set(obj.Elem())
+	return nil
+}
+
+func decodeJSONValue(data []byte) (interface{}, error) {
+	var value interface{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// applyPatchOp applies one RFC 6902 operation to doc. An attribute that is removed is reset to
+// its value in defaults, the json form of the default object. It returns the new document and
+// the names of the attributes the operation modifies.
+func applyPatchOp(doc interface{}, names []string, defaults interface{}, op JSONPatchOp) (interface{}, []string, error) {
+	path, err := parsePointer(op.Path)
+	if err != nil {
+		return doc, nil, err
+	}
+	var from []string
+	if op.Op == "move" || op.Op == "copy" {
+		if op.From == "" {
+			return doc, nil, errors.New("missing from")
+		}
+		if from, err = parsePointer(op.From); err != nil {
+			return doc, nil, err
+		}
+	}
+	var value interface{}
+	if op.Op == "add" || op.Op == "replace" || op.Op == "test" {
+		if op.Value == nil {
+			return doc, nil, errors.New("missing value")
+		}
+		if value, err = decodeJSONValue(op.Value); err != nil {
+			return doc, nil, errors.New("invalid value: " + err.Error())
+		}
+	}
+	if op.Op != "test" && op.Op != "remove" && len(path) == 1 && !isAttrName(names, path[0]) {
+		return doc, nil, errors.New("unknown attribute " + path[0])
+	}
+
+	switch op.Op {
+	case "add":
+		doc, err = pointerAdd(doc, path, value)
+	case "remove":
+		if doc, _, err = pointerRemove(doc, path); err == nil {
+			doc = resetAttr(doc, defaults, path)
+		}
+	case "replace":
+		doc, err = pointerReplace(doc, path, value)
+	case "move":
+		if len(from) < len(path) && isPointerPrefix(from, path) {
+			return doc, nil, errors.New("can not move a value into one of its children")
+		}
+		var moved interface{}
+		if doc, moved, err = pointerRemove(doc, from); err == nil {
+			doc, err = pointerAdd(resetAttr(doc, defaults, from), path, moved)
+		}
+	case "copy":
+		var copied interface{}
+		if copied, err = pointerGet(doc, from); err == nil {
+			doc, err = pointerAdd(doc, path, copyJSONValue(copied))
+		}
+	case "test":
+		var current interface{}
+		if current, err = pointerGet(doc, path); err == nil && !jsonEqual(current, value) {
+			err = errors.New("test failed")
+		}
+		return doc, nil, err
+	default:
+		return doc, nil, errors.New("unknown operation " + strconv.Quote(op.Op))
+	}
+	if err != nil {
+		return doc, nil, err
+	}
+	touched := pointerAttrs(names, path)
+	if op.Op == "move" {
+		touched = append(pointerAttrs(names, from), touched...)
+	}
+	return doc, touched, nil
+}
+
+// resetAttr sets the attribute at path, if path is that of a whole attribute, back to its value
+// in defaults
+func resetAttr(doc interface{}, defaults interface{}, path []string) interface{} {
+	members, ok := doc.(map[string]interface{})
+	defaultMembers, isObject := defaults.(map[string]interface{})
+	if ok && isObject && len(path) == 1 {
+		members[path[0]] = copyJSONValue(defaultMembers[path[0]])
+	}
+	return doc
+}
+
+// checkKeys returns an error if the key attributes keys have other values in doc than in orig,
+// the json forms of an object before and after it is patched
+func checkKeys(keys []string, orig interface{}, doc interface{}) error {
+	origMembers, _ := orig.(map[string]interface{})
+	members, ok := doc.(map[string]interface{})
+	if !ok {
+		// The patched document is not an object, which fails when it is decoded
+		return nil
+	}
+	for _, key := range keys {
+		if !jsonEqual(origMembers[key], members[key]) {
+			return errors.New("key attribute " + key + " can not be changed")
+		}
+	}
+	return nil
+}
+
+// applyAttrPatchOp applies an operation whose path is an attribute name to doc
+func applyAttrPatchOp(doc interface{}, names []string, op JSONPatchOp) ([]string, error) {
+	members, ok := doc.(map[string]interface{})
+	if !ok || !isAttrName(names, op.Path) {
+		return nil, errors.New("unknown attribute " + op.Path)
+	}
+	if op.Value == nil {
+		return nil, errors.New("missing value")
+	}
+	value, err := decodeJSONValue(op.Value)
+	if err != nil {
+		return nil, errors.New("invalid value: " + err.Error())
+	}
+	if op.Op == "replace" {
+		members[op.Path] = value
+		return []string{op.Path}, nil
+	}
+	if op.Op != "add" && op.Op != "remove" {
+		return nil, errors.New("unknown operation " + strconv.Quote(op.Op))
+	}
+	elems, isArray := members[op.Path].([]interface{})
+	values, ok := value.([]interface{})
+	if !isArray || !ok {
+		return nil, errors.New(op.Op + " needs an array attribute and an array value")
+	}
+	if op.Op == "add" {
+		members[op.Path] = append(elems, values...)
+		return []string{op.Path}, nil
+	}
+	for _, v := range values {
+		for i, elem := range elems {
+			if jsonEqual(elem, v) {
+				elems = append(elems[:i:i], elems[i+1:]...)
+				break
+			}
+		}
+	}
+	members[op.Path] = elems
+	return []string{op.Path}, nil
+}
+
+// applyMergePatch applies the RFC 7386 JSON Merge Patch document patch to the json form of *src
+// and decodes the result into *dst. A null attribute in the patch is reset to its value in
+// *defaults, a null member of a struct attribute to its zero value. The value of the key
+// attributes keys can not be changed. It returns the attributes whose value changed.
+func applyMergePatch(objName string, names []string, keys []string, src interface{}, defaults interface{}, dst interface{}, patch []byte) (AttrSet, error) {
+	attrs := newAttrSet(names)
+	patchDoc, err := decodeJSONValue(patch)
+	if err != nil {
+		return attrs, &PatchError{Object: objName, Index: -1, Msg: "invalid merge patch document: " + err.Error()}
+	}
+	patchMembers, ok := patchDoc.(map[string]interface{})
+	if !ok {
+		return attrs, &PatchError{Object: objName, Index: -1, Msg: "merge patch document is not a json object"}
+	}
+	doc, err := jsonDocument(src)
+	if err != nil {
+		return attrs, &PatchError{Object: objName, Index: -1, Msg: err.Error()}
+	}
+	defaultDoc, err := jsonDocument(defaults)
+	if err != nil {
+		return attrs, &PatchError{Object: objName, Index: -1, Msg: err.Error()}
+	}
+	members := copyJSONValue(doc).(map[string]interface{})
+	defaultMembers := defaultDoc.(map[string]interface{})
+	for name, value := range patchMembers {
+		if !isAttrName(names, name) {
+			return attrs, &PatchError{Object: objName, Index: -1, Msg: "unknown attribute " + name}
+		}
+		if value == nil {
+			members[name] = defaultMembers[name]
+		} else {
+			members[name] = mergePatchValue(members[name], value)
+		}
+	}
+	if err := checkKeys(keys, doc, members); err != nil {
+		return attrs, &PatchError{Object: objName, Index: -1, Msg: err.Error()}
+	}
+	data, err := json.Marshal(members)
+	if err == nil {
+		err = decodeObject(data, dst)
+	}
+	var merged interface{}
+	if err == nil {
+		merged, err = jsonDocument(dst)
+	}
+	if err != nil {
+		return attrs, &PatchError{Object: objName, Index: -1, Msg: "patched object is invalid: " + err.Error()}
+	}
+	// Compare the decoded object so that values that only differ in their json form, such as
+	// 1 and 1.0, are not reported as changed
+	mergedMembers := merged.(map[string]interface{})
+	for id, name := range names {
+		if !jsonEqual(doc.(map[string]interface{})[name], mergedMembers[name]) {
+			attrs.Add(AttrId(id))
+		}
+	}
+	return attrs, nil
+}
+
+// mergePatchValue returns target merged with the patch value, as the MergePatch function of
+// RFC 7386 does
+func mergePatchValue(target interface{}, patch interface{}) interface{} {
+	patchMembers, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	targetMembers, ok := target.(map[string]interface{})
+	if !ok {
+		targetMembers = make(map[string]interface{})
+	}
+	for name, value := range patchMembers {
+		if value == nil {
+			delete(targetMembers, name)
+		} else {
+			targetMembers[name] = mergePatchValue(targetMembers[name], value)
+		}
+	}
+	return targetMembers
+}
+
+// parsePointer splits an RFC 6901 JSON Pointer into its unescaped reference tokens. The empty
+// pointer, the whole document, has no tokens.
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, errors.New("invalid JSON pointer " + strconv.Quote(pointer))
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		for j := 0; j < len(token); j++ {
+			if token[j] == '~' && (j+1 == len(token) || (token[j+1] != '0' && token[j+1] != '1')) {
+				return nil, errors.New("invalid escape in JSON pointer " + strconv.Quote(pointer))
+			}
+		}
+		tokens[i] = strings.Replace(strings.Replace(token, "~1", "/", -1), "~0", "~", -1)
+	}
+	return tokens, nil
+}
+
+func isPointerPrefix(prefix []string, path []string) bool {
+	if len(prefix) > len(path) {
+		return false
+	}
+	for i := range prefix {
+		if prefix[i] != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// pointerAttrs returns the attributes a change at path modifies: all of them for the whole object
+func pointerAttrs(names []string, path []string) []string {
+	if len(path) == 0 {
+		return append([]string(nil), names...)
+	}
+	return []string{path[0]}
+}
+
+func isAttrName(names []string, name string) bool {
+	for _, attrName := range names {
+		if attrName == name {
+			return true
+		}
+	}
+	return false
+}
+
+// arrayIndex parses the array index token of a pointer to an array of length elements. "-",
+// past the last element, is only valid if pastEnd is set.
+func arrayIndex(token string, length int, pastEnd bool) (int, error) {
+	if token == "-" && pastEnd {
+		return length, nil
+	}
+	idx, err := strconv.Atoi(token)
+	if err != nil || idx < 0 || (len(token) > 1 && token[0] == '0') || token[0] == '+' {
+		return 0, errors.New("invalid array index " + strconv.Quote(token))
+	}
+	if idx > length || (idx == length && !pastEnd) {
+		return 0, errors.New("array index " + token + " is out of range")
+	}
+	return idx, nil
+}
+
+func pointerGet(doc interface{}, path []string) (interface{}, error) {
+	for _, token := range path {
+		switch container := doc.(type) {
+		case map[string]interface{}:
+			value, exist := container[token]
+			if !exist {
+				return nil, errors.New("path not found: member " + strconv.Quote(token) + " does not exist")
+			}
+			doc = value
+		case []interface{}:
+			idx, err := arrayIndex(token, len(container), false)
+			if err != nil {
+				return nil, err
+			}
+			doc = container[idx]
+		default:
+			return nil, errors.New("path not found: " + strconv.Quote(token) + " is not in an object or array")
+		}
+	}
+	return doc, nil
+}
+
+// pointerUpdate returns doc after calling update on the container holding the value at path and
+// the last token of path. update returns the new container.
+func pointerUpdate(doc interface{}, path []string, update func(container interface{}, token string) (interface{}, error)) (interface{}, error) {
+	if len(path) == 1 {
+		return update(doc, path[0])
+	}
+	child, err := pointerGet(doc, path[:1])
+	if err != nil {
+		return doc, err
+	}
+	if child, err = pointerUpdate(child, path[1:], update); err != nil {
+		return doc, err
+	}
+	switch container := doc.(type) {
+	case map[string]interface{}:
+		container[path[0]] = child
+	case []interface{}:
+		idx, _ := arrayIndex(path[0], len(container), false)
+		container[idx] = child
+	}
+	return doc, nil
+}
+
+func pointerAdd(doc interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	return pointerUpdate(doc, path, func(container interface{}, token string) (interface{}, error) {
+		switch c := container.(type) {
+		case map[string]interface{}:
+			c[token] = value
+			return c, nil
+		case []interface{}:
+			idx, err := arrayIndex(token, len(c), true)
+			if err != nil {
+				return c, err
+			}
+			c = append(c, nil)
+			copy(c[idx+1:], c[idx:])
+			c[idx] = value
+			return c, nil
+		}
+		return container, errors.New("path not found: " + strconv.Quote(token) + " is not in an object or array")
+	})
+}
+
+func pointerRemove(doc interface{}, path []string) (interface{}, interface{}, error) {
+	if len(path) == 0 {
+		return doc, nil, errors.New("can not remove the whole object")
+	}
+	var removed interface{}
+	doc, err := pointerUpdate(doc, path, func(container interface{}, token string) (interface{}, error) {
+		value, err := pointerGet(container, []string{token})
+		if err != nil {
+			return container, err
+		}
+		removed = value
+		switch c := container.(type) {
+		case map[string]interface{}:
+			delete(c, token)
+			return c, nil
+		case []interface{}:
+			idx, _ := arrayIndex(token, len(c), false)
+			return append(c[:idx:idx], c[idx+1:]...), nil
+		}
+		return container, nil
+	})
+	return doc, removed, err
+}
+
+func pointerReplace(doc interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	return pointerUpdate(doc, path, func(container interface{}, token string) (interface{}, error) {
+		if _, err := pointerGet(container, []string{token}); err != nil {
+			return container, err
+		}
+		switch c := container.(type) {
+		case map[string]interface{}:
+			c[token] = value
+		case []interface{}:
+			idx, _ := arrayIndex(token, len(c), false)
+			c[idx] = value
+		}
+		return container, nil
+	})
+}
+
+func copyJSONValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(v))
+		for key, elem := range v {
+			copied[key] = copyJSONValue(elem)
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(v))
+		for i, elem := range v {
+			copied[i] = copyJSONValue(elem)
+		}
+		return copied
+	}
+	return value
+}
+
+// jsonEqual compares two decoded json values as the test operation does: numbers by value,
+// arrays element by element and objects member by member
+func jsonEqual(a interface{}, b interface{}) bool {
+	switch va := a.(type) {
+	case json.Number:
+		vb, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		ra, okA := new(big.Rat).SetString(string(va))
+		rb, okB := new(big.Rat).SetString(string(vb))
+		return okA && okB && ra.Cmp(rb) == 0
+	case []interface{}:
+		vb, ok := b.([]interface{})
+		if !ok || len(va) != len(vb) {
+			return false
+		}
+		for i := range va {
+			if !jsonEqual(va[i], vb[i]) {
+				return false
+			}
+		}
+		return true
+	case map[string]interface{}:
+		vb, ok := b.(map[string]interface{})
+		if !ok || len(va) != len(vb) {
+			return false
+		}
+		for key, elem := range va {
+			other, exist := vb[key]
+			if !exist || !jsonEqual(elem, other) {
+				return false
+			}
+		}
+		return true
+	}
+	return a == b
+}
+`
+
+// WritePatchFcns writes the functions applying JSON Patch and JSON Merge Patch documents to a
+// config object. MergeDbAndConfigObjForPatchUpdate takes the operations in the PatchOpInfo form.
+// The defaults removed attributes are reset to are those UnmarshalObject sets.
+func (obj *ObjectInfoJson) WritePatchFcns(buf *bytes.Buffer, attrMap []ObjectMemberAndInfo, objMap map[string]ObjectInfoJson) {
+	var lines []string
+	keysVar := "keyNames" + obj.ObjName
+	lines = append(lines, "\nvar "+keysVar+" = []string{\n")
+	for _, attrInfo := range attrMap {
+		if attrInfo.IsKey {
+			lines = append(lines, strconv.Quote(attrInfo.MemberName)+",\n")
+		}
+	}
+	lines = append(lines, "}\n")
+	lines = append(lines, `
+		func (obj `+obj.ObjName+`) MergeDbAndConfigObjForPatchUpdate(dbObj ConfigObj, patchOpInfoSlice []PatchOpInfo) (ConfigObj, []bool, error) {
+			mergedObject, attrs, err := obj.applyPatchOpInfo(dbObj, patchOpInfoSlice)
+			return mergedObject, attrs.Bools(), err
+		}
+
+		// ApplyJSONPatch applies the RFC 6902 JSON Patch document patch to dbObj. It returns the
+		// patched object and the attributes the patch modifies.
+		func (obj `+obj.ObjName+`) ApplyJSONPatch(dbObj ConfigObj, patch []byte) (ConfigObj, AttrSet, error) {
+			ops, err := parseJSONPatch("`+obj.ObjName+`", patch)
+			if err != nil {
+				return dbObj, obj.NewAttrSet(), err
+			}
+			return obj.applyPatch(dbObj, ops)
+		}
+
+		func (obj `+obj.ObjName+`) applyPatchOpInfo(dbObj ConfigObj, patchOpInfoSlice []PatchOpInfo) (ConfigObj, AttrSet, error) {
+			ops, err := patchOpsFromPatchOpInfo("`+obj.ObjName+`", patchOpInfoSlice)
+			if err != nil {
+				return dbObj, obj.NewAttrSet(), err
+			}
+			return obj.applyPatch(dbObj, ops)
+		}
+
+		func (obj `+obj.ObjName+`) applyPatch(dbObj ConfigObj, ops []JSONPatchOp) (ConfigObj, AttrSet, error) {
+			srcObject := dbObj.(`+obj.ObjName+`)
+			defaultObj, err := `+obj.ObjName+`{}.UnmarshalObject(nil)
+			if err != nil {
+				return dbObj, obj.NewAttrSet(), err
+			}
+			defaultObject := defaultObj.(`+obj.ObjName+`)
+			var mergedObject `+obj.ObjName+`
+			attrs, err := applyJSONPatch("`+obj.ObjName+`", attrNames`+obj.ObjName+`, `+keysVar+`, &srcObject, &defaultObject, &mergedObject, ops)
+			if err != nil {
+				return dbObj, attrs, err
+			}
+			return mergedObject, attrs, nil
+		}
+
+		// MergePatch applies the RFC 7386 JSON Merge Patch document patch to dbObj. An attribute
+		// set to null is reset to its default. It returns the patched object and the attributes
+		// whose value changed.
+		func (obj `+obj.ObjName+`) MergePatch(dbObj ConfigObj, patch []byte) (ConfigObj, AttrSet, error) {
+			srcObject := dbObj.(`+obj.ObjName+`)
+			defaultObj, err := `+obj.ObjName+`{}.UnmarshalObject(nil)
+			if err != nil {
+				return dbObj, obj.NewAttrSet(), err
+			}
+			defaultObject := defaultObj.(`+obj.ObjName+`)
+			var mergedObject `+obj.ObjName+`
+			attrs, err := applyMergePatch("`+obj.ObjName+`", attrNames`+obj.ObjName+`, `+keysVar+`, &srcObject, &defaultObject, &mergedObject, patch)
+			if err != nil {
+				return dbObj, attrs, err
+			}
+			return mergedObject, attrs, nil
+		}
+		`)
+	for _, line := range lines {
+		buf.WriteString(line)
+	}
+}
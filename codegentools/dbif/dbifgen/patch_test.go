@@ -0,0 +1,54 @@
+package dbifgen
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// TestPatchCode runs the tests in testdata/patch against the code the generator writes into the
+// common file of an objects package. That code is only compiled in the generated package, so the
+// tests are built with it in a package of their own.
+func TestPatchCode(t *testing.T) {
+	goCmd, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not found")
+	}
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":    "module models/objects\n\ngo 1.24\n",
+		COMMON_FILE: "package objects\n" + commonCode(false),
+	}
+	srcs, err := filepath.Glob(filepath.Join("testdata", "patch", "*.go"))
+	if err != nil || len(srcs) == 0 {
+		t.Fatal("No tests in testdata/patch", err)
+	}
+	for _, src := range srcs {
+		data, err := ioutil.ReadFile(src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[filepath.Base(src)] = string(data)
+	}
+	for name, data := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	args := []string{"test", "-count=1"}
+	if testing.Verbose() {
+		args = append(args, "-v")
+	}
+	cmd := exec.Command(goCmd, append(args, ".")...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GO111MODULE=on", "GOFLAGS=-mod=mod", "GOWORK=off")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("Tests of the patch code failed: %v\n%s", err, out)
+	}
+	if testing.Verbose() {
+		t.Logf("%s", out)
+	}
+}
@@ -0,0 +1,78 @@
+package dbifgen
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+)
+
+// ValueRange is one interval of a RANGE tag. An empty bound is open. The bounds are kept as
+// decimal numbers so that they fit any integer type, uint64 included.
+type ValueRange struct {
+	Min json.Number `json:"min,omitempty"`
+	Max json.Number `json:"max,omitempty"`
+}
+
+// One interval of a RANGE value: a single number, or two bounds separated by '-' where a
+// missing bound, min or max is open. A leading '-' is the sign of a number, so an interval
+// without lower bound has to be written as min-10.
+var rangeInterval = regexp.MustCompile(`^\s*(-?[0-9]+|min)?\s*(?:(-)\s*(-?[0-9]+|max)?)?\s*$`)
+
+// parseRanges parses the value of a RANGE tag. The syntax is a list of intervals separated by '|':
+//
+//	RANGE: 1-4094
+//	RANGE: 1-10|20-30
+//	RANGE: 100-       (100 and above, same as 100-max)
+//	RANGE: min-0      (0 and below)
+//	RANGE: -10--5|5   (negative bounds and a single value)
+func parseRanges(value string) ([]ValueRange, error) {
+	var ranges []ValueRange
+	for _, part := range strings.Split(value, "|") {
+		match := rangeInterval.FindStringSubmatch(part)
+		if match == nil || (match[1] == "" && match[2] == "") {
+			return nil, errors.New(fmt.Sprintln("Invalid range", strings.TrimSpace(part), "in RANGE", value))
+		}
+		var r ValueRange
+		if match[1] != "min" {
+			r.Min = json.Number(match[1])
+		}
+		switch {
+		case match[2] == "":
+			// A single value
+			r.Max = r.Min
+		case match[3] != "max":
+			r.Max = json.Number(match[3])
+		}
+		if r.Min == "" && r.Max == "" {
+			return nil, errors.New(fmt.Sprintln("Range", strings.TrimSpace(part), "in RANGE", value, "has no bounds"))
+		}
+		if r.Min != "" && r.Max != "" && rangeBound(r.Min).Cmp(rangeBound(r.Max)) > 0 {
+			return nil, errors.New(fmt.Sprintln("Lower bound of range", strings.TrimSpace(part), "in RANGE", value, "is greater than its upper bound"))
+		}
+		ranges = append(ranges, r)
+	}
+	return ranges, nil
+}
+
+func rangeBound(n json.Number) *big.Int {
+	bound, _ := new(big.Int).SetString(string(n), 10)
+	return bound
+}
+
+// rangesSpan returns the lowest and the highest value allowed by ranges. A bound is empty if
+// the ranges are open on that side.
+func rangesSpan(ranges []ValueRange) (json.Number, json.Number) {
+	var low, high json.Number
+	for idx, r := range ranges {
+		if idx == 0 || (low != "" && (r.Min == "" || rangeBound(r.Min).Cmp(rangeBound(low)) < 0)) {
+			low = r.Min
+		}
+		if idx == 0 || (high != "" && (r.Max == "" || rangeBound(r.Max).Cmp(rangeBound(high)) > 0)) {
+			high = r.Max
+		}
+	}
+	return low, high
+}
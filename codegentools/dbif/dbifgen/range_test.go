@@ -0,0 +1,78 @@
+package dbifgen
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestParseRanges(t *testing.T) {
+	tests := []struct {
+		value string
+		want  []ValueRange
+	}{
+		{"1-4094", []ValueRange{{Min: "1", Max: "4094"}}},
+		{"1-10|20-30", []ValueRange{{Min: "1", Max: "10"}, {Min: "20", Max: "30"}}},
+		{"100-", []ValueRange{{Min: "100"}}},
+		{"100-max", []ValueRange{{Min: "100"}}},
+		{"min-0", []ValueRange{{Max: "0"}}},
+		{"-10--5|5", []ValueRange{{Min: "-10", Max: "-5"}, {Min: "5", Max: "5"}}},
+		{"-5", []ValueRange{{Min: "-5", Max: "-5"}}},
+		{" 1 - 10 | 20 ", []ValueRange{{Min: "1", Max: "10"}, {Min: "20", Max: "20"}}},
+		{"0-18446744073709551615", []ValueRange{{Min: "0", Max: "18446744073709551615"}}},
+	}
+	for _, test := range tests {
+		got, err := parseRanges(test.value)
+		if err != nil {
+			t.Errorf("parseRanges(%q) failed: %v", test.value, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("parseRanges(%q) = %v, want %v", test.value, got, test.want)
+		}
+	}
+}
+
+func TestParseRangesErrors(t *testing.T) {
+	tests := []string{
+		"",
+		"-",
+		"min-max",
+		"min",
+		"10-1",
+		"-5--10",
+		"1-10|",
+		"1..10",
+		"a-b",
+		"1-2-3",
+		"0x10-0x20",
+	}
+	for _, value := range tests {
+		if got, err := parseRanges(value); err == nil {
+			t.Errorf("parseRanges(%q) = %v, want an error", value, got)
+		}
+	}
+}
+
+func TestRangesSpan(t *testing.T) {
+	tests := []struct {
+		value     string
+		low, high json.Number
+	}{
+		{"1-4094", "1", "4094"},
+		{"20-30|1-10", "1", "30"},
+		{"-10--5|5", "-10", "5"},
+		{"1-10|100-", "1", ""},
+		{"min-0|5-10", "", "10"},
+	}
+	for _, test := range tests {
+		ranges, err := parseRanges(test.value)
+		if err != nil {
+			t.Errorf("parseRanges(%q) failed: %v", test.value, err)
+			continue
+		}
+		if low, high := rangesSpan(ranges); low != test.low || high != test.high {
+			t.Errorf("rangesSpan(%q) = %q, %q, want %q, %q", test.value, low, high, test.low, test.high)
+		}
+	}
+}
@@ -0,0 +1,247 @@
+package dbifgen
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+func (gen *Generator) generateSerializers(model *Model, objectsByOwner map[string][]ObjectInfoJson, errs *ErrorList) error {
+	for _, owner := range sortedOwners(objectsByOwner) {
+		objList := objectsByOwner[owner]
+		marshalFcnFile := filepath.Join(gen.SrcDir, "gen_"+owner+"Objects_serializer.go")
+		if len(objList) > 0 && !gen.skip(marshalFcnFile, gen.ownerInputs(model, objList)...) {
+			//if owner != "lacpd" { //|| owner != "ospfd" {
+			err := gen.generateUnmarshalFcn(model, owner, objList)
+			if err = gen.fail(errs, err, "", PHASE_SERIALIZER); err != nil {
+				return err
+			}
+			//}
+		}
+	}
+	return nil
+}
+
+func (gen *Generator) generateUnmarshalFcn(model *Model, ownerName string, objList []ObjectInfoJson) error {
+	var marshalFcnsLine []string
+	var objIf string
+	var usesStrconv bool
+	// Packages of the types the serializer names, keyed by the name they are imported as
+	pkgs := make(map[string]string)
+	if model.Actions {
+		objIf = ACTIONS_INTERFACE
+	} else {
+		objIf = OBJECTS_INTERFACE
+	}
+	marshalFcnFile := filepath.Join(gen.SrcDir, "gen_"+ownerName+"Objects_serializer.go")
+	logger.Debug("Writing serializers", LOG_KEY_PHASE, PHASE_SERIALIZER, LOG_KEY_OWNER, ownerName, LOG_KEY_FILE, marshalFcnFile)
+	var marshalFcnFd bytes.Buffer
+	for _, obj := range objList {
+		//fmt.Println("Object Name for Unmarshal ", obj.ObjName)
+		if strings.Contains(obj.Access, "w") || strings.Contains(obj.Access, "r") || strings.Contains(obj.Access, "x") {
+			objMembers, exist := model.Members[obj.ObjName]
+			if !exist && gen.KeepGoing {
+				// The failure to load the members has already been reported
+				logger.Warn("Members of object are not known", LOG_KEY_PHASE, PHASE_SERIALIZER, LOG_KEY_OBJECT, obj.ObjName, LOG_KEY_OWNER, ownerName)
+				continue
+			}
+			if !exist {
+				return &GenError{Object: obj.ObjName, Phase: PHASE_SERIALIZER, Err: errors.New("Members of the object are not known")}
+			}
+			if model.Actions {
+				marshalFcnsLine = append(marshalFcnsLine, "\nfunc (obj "+obj.ObjName+") UnmarshalAction(body []byte) ("+objIf+", error) {\n")
+			} else {
+				marshalFcnsLine = append(marshalFcnsLine, "\nfunc (obj "+obj.ObjName+") UnmarshalObject(body []byte) ("+objIf+", error) {\n")
+
+			}
+			marshalFcnsLine = append(marshalFcnsLine, "var err error \n")
+
+			// Check all attributes and write default constructor
+			for _, attrInfo := range obj.ConvertObjectMembersMapToOrderedSlice(objMembers) {
+				attrName := attrInfo.MemberName
+				if attrInfo.IsDefaultSet {
+					if attrInfo.IsArray {
+						marshalFcnsLine = append(marshalFcnsLine, "obj."+attrName+"= make([]"+attrInfo.VarType+", 0)"+"\n")
+						attrInfo.GoType.packages(pkgs)
+					} else if attrInfo.GoType != nil && attrInfo.BaseType == "" {
+						// There is no literal for the default of a nested type, it stays the zero value
+						continue
+					} else if attrInfo.baseType() == "string" {
+						marshalFcnsLine = append(marshalFcnsLine, "obj."+attrName+" = "+strconv.Quote(attrInfo.DefaultVal)+"\n")
+					} else {
+						marshalFcnsLine = append(marshalFcnsLine, "obj."+attrName+" = "+attrInfo.DefaultVal+"\n")
+					}
+				}
+			}
+			marshalFcnsLine = append(marshalFcnsLine, `
+													if len(body) > 0 {
+													    err = unmarshalObject("`+obj.ObjName+`", body, &obj, `+strconv.FormatBool(obj.LenientUnmarshal)+`)
+													   }
+													   return obj, err
+													}
+													`)
+			//fmt.Println(marshalFcnsLine)
+
+			if strings.Contains(obj.Access, "w") || strings.Contains(obj.Access, "r") {
+				lines, parses, err := obj.writeUnmarshalObjectDataFcn(obj.ConvertObjectMembersMapToOrderedSlice(objMembers), pkgs)
+				if err != nil {
+					return &GenError{Object: obj.ObjName, Phase: PHASE_SERIALIZER, Err: err}
+				}
+				marshalFcnsLine = append(marshalFcnsLine, lines...)
+				usesStrconv = usesStrconv || parses
+			}
+		}
+	}
+	if len(marshalFcnsLine) > 0 {
+		packageLine := "package " + gen.PackageName
+		marshalFcnFd.WriteString(packageLine)
+		imports := importLines(pkgs)
+		if usesStrconv {
+			imports = append([]string{"\"strconv\"\n"}, imports...)
+		}
+		if len(imports) > 0 {
+			marshalFcnFd.WriteString("\n\nimport (\n")
+			for _, line := range imports {
+				marshalFcnFd.WriteString(line)
+			}
+			marshalFcnFd.WriteString(")")
+		}
+
+		for _, marshalLine := range marshalFcnsLine {
+			marshalFcnFd.WriteString(string(marshalLine))
+		}
+	}
+	return gen.writeFile(marshalFcnFile, marshalFcnFd.Bytes())
+}
+
+// writeUnmarshalObjectDataFcn returns the UnmarshalObjectData method of the object, which sets
+// the attributes given as query parameters. Parameter names are case insensitive and array
+// members take every value of a repeated parameter. If some members have a QPARAM tag, only
+// those are query parameters and the ones tagged QPARAM: mandatory have to be given. Names that
+// are not query parameters of the object are ignored, whether or not it has QPARAM tags, so that
+// clients may pass parameters meant for others, such as paging, along. Numbers
+// have to fit the member type and the bounds of its RANGE or MIN and MAX tags. Members of named
+// types, also of other packages, are given as values of their basic type. Members that are not of
+// a basic type can not be query parameters. The packages of the member types the method names are
+// added to pkgs. The second result is true if the method parses values with strconv.
+func (obj *ObjectInfoJson) writeUnmarshalObjectDataFcn(attrMap []ObjectMemberAndInfo, pkgs map[string]string) ([]string, bool, error) {
+	var lines []string
+	usesStrconv := false
+	hasQueryParams := false
+	for _, attrInfo := range attrMap {
+		if attrInfo.QueryParam != "" {
+			hasQueryParams = true
+		}
+	}
+	lines = append(lines, "\n// UnmarshalObjectData sets the attributes of "+obj.ObjName+" given in queryMap. Names are case insensitive.\n")
+	if hasQueryParams {
+		lines = append(lines, "// Only the attributes tagged QPARAM are query parameters.\n")
+	}
+	lines = append(lines, "// Names that are not query parameters of "+obj.ObjName+" are ignored.\n",
+		"func (obj "+obj.ObjName+") UnmarshalObjectData(queryMap map[string][]string) (ConfigObj, error) {\n")
+	lines = append(lines, "retObj := "+obj.ObjName+"{}\n")
+	lines = append(lines, "params, err := queryParams(\""+obj.ObjName+"\", queryMap)\n",
+		"if err != nil {\nreturn retObj, err\n}\n")
+	for _, attrInfo := range attrMap {
+		attrName := attrInfo.MemberName
+		paramName := strconv.Quote(strings.ToLower(attrName))
+		if hasQueryParams && attrInfo.QueryParam == "" {
+			continue
+		}
+		parse, parses := queryParamParse(obj.ObjName, attrName, attrInfo.baseType())
+		if parse == "" && attrInfo.QueryParam != "" {
+			return nil, false, errors.New(fmt.Sprintln("Member", attrName, "of type", attrInfo.VarType, "can not be a query parameter, it is not of a basic type"))
+		}
+		if parse == "" {
+			continue
+		}
+		attrInfo.GoType.packages(pkgs)
+		if attrInfo.baseType() != "bool" && attrInfo.baseType() != "string" {
+			ranges, err := numericRanges(attrInfo)
+			if err != nil {
+				return nil, false, errors.New(fmt.Sprintln("Member", attrName+":", strings.TrimSpace(err.Error())))
+			}
+			if cond := rangesCond("val", attrInfo.baseType(), ranges); cond != "" {
+				parse += "if " + cond + " {\n" +
+					"return retObj, &QueryParamError{Object: \"" + obj.ObjName + "\", Attr: \"" + attrName + "\", Value: param, Msg: " +
+					strconv.Quote("out of range "+rangesText(ranges)) + "}\n" +
+					"}\n"
+			}
+		}
+		usesStrconv = usesStrconv || parses
+		// val is of the basic type, members of a named type need it converted
+		value := "val"
+		if attrInfo.BaseType != "" {
+			value = attrInfo.VarType + "(val)"
+		}
+		lines = append(lines, "if vals, ok := params["+paramName+"]; ok && len(vals) > 0 {\n")
+		if attrInfo.IsArray {
+			lines = append(lines, "for _, param := range vals {\n", parse,
+				"retObj."+attrName+" = append(retObj."+attrName+", "+value+")\n",
+				"}\n")
+		} else {
+			lines = append(lines, "param := vals[0]\n", parse,
+				"retObj."+attrName+" = "+value+"\n")
+		}
+		lines = append(lines, "}")
+		if strings.EqualFold(attrInfo.QueryParam, "mandatory") {
+			lines = append(lines, " else {\n",
+				"return retObj, &QueryParamError{Object: \""+obj.ObjName+"\", Attr: \""+attrName+"\", Msg: \"missing mandatory query parameter\"}\n",
+				"}")
+		}
+		lines = append(lines, "\n")
+	}
+	lines = append(lines, "return retObj, nil\n}\n")
+	return lines, usesStrconv, nil
+}
+
+// queryParamParse returns the code setting val to the query parameter value param, converted to
+// varType. Values that do not fit varType are rejected. The code is empty for types that can not
+// be given as query parameters. The second result is true if the code uses strconv.
+func queryParamParse(objName string, attrName string, varType string) (string, bool) {
+	var parse string
+	switch varType {
+	case "string":
+		return "val := param\n", false
+	case "bool":
+		parse = "strconv.ParseBool(param)"
+	case "int", "int8", "int16", "int32", "int64":
+		parse = "strconv.ParseInt(param, 10, " + intBitSize(varType) + ")"
+	case "uint", "uint8", "uint16", "uint32", "uint64", "byte":
+		parse = "strconv.ParseUint(param, 10, " + intBitSize(varType) + ")"
+	case "float32":
+		parse = "strconv.ParseFloat(param, 32)"
+	case "float64":
+		parse = "strconv.ParseFloat(param, 64)"
+	default:
+		return "", false
+	}
+	conversion := varType + "(parsed)"
+	if varType == "bool" || varType == "float64" {
+		conversion = "parsed"
+	}
+	return "parsed, err := " + parse + "\n" +
+		"if err != nil {\n" +
+		"return retObj, queryParamError(\"" + objName + "\", \"" + attrName + "\", param, \"" + varType + "\", err)\n" +
+		"}\n" +
+		"val := " + conversion + "\n", true
+}
+
+// intBitSize returns the bitSize argument of strconv.ParseInt/ParseUint for the integer type
+// varType. 0 stands for the size of int and uint.
+func intBitSize(varType string) string {
+	switch varType {
+	case "int8", "uint8", "byte":
+		return "8"
+	case "int16", "uint16":
+		return "16"
+	case "int32", "uint32":
+		return "32"
+	case "int64", "uint64":
+		return "64"
+	}
+	return "0"
+}
@@ -0,0 +1,189 @@
+package dbifgen
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Model struct tags
+//
+// The members of the model structures carry their attributes in the struct tag:
+//
+//	Mtu uint16 `DESCRIPTION: "MTU of the interface, in bytes", MIN: "64", MAX: "9000", DEFAULT: 1500`
+//
+// A tag is a comma separated list of entries. An entry is either a NAME on its own, e.g.
+// ACCELERATED, or a NAME followed by a colon and a value. Names are made of letters, digits
+// and underscores and do not start with a digit. Every name may appear only once.
+//
+// A value is either quoted or unquoted:
+//   - A quoted value is a double quoted string with the escapes of a Go string literal, so it
+//     may hold any text including commas, colons and \" . Only spaces may follow it up to the
+//     comma that starts the next entry.
+//   - An unquoted value runs up to the comma that starts the next entry. A comma starts the next
+//     entry when it is followed by an upper case NAME and a colon, by one of the flag names
+//     in tagFlags, or by nothing but spaces. Any other comma is part of the value, and so are
+//     double quotes.
+//
+// A value that starts with a double quote but is not a whole quoted value, such as
+// `DESCRIPTION: "admin" state`, is unquoted.
+//
+// Spaces around names, colons and values are ignored. pybind writes unquoted descriptions, so
+// `DESCRIPTION: Vlan id, tagged or not, MIN: "1"` is a description "Vlan id, tagged or not" and
+// a MIN of 1, and `DESCRIPTION: Cable length 5" max` keeps its quote. A description that itself
+// contains a comma followed by NAME: has to be quoted.
+
+// Tag names that stand on their own, without a value
+var tagFlags = map[string]bool{
+	"ACCELERATED":      true,
+	"USESTATEDB":       true,
+	"AUTOCREATE":       true,
+	"AUTODISCOVER":     true,
+	"LENIENTUNMARSHAL": true,
+}
+
+var tagNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*`)
+var tagBoundary = regexp.MustCompile(`^,\s*(([A-Z][A-Z0-9_]*)\s*(:|,|$)|$)`)
+
+// TagEntry is one entry of a model struct tag
+type TagEntry struct {
+	Name     string
+	Value    string
+	HasValue bool
+}
+
+// ModelTag holds the entries of a model struct tag in the order they appear in
+type ModelTag []TagEntry
+
+// Lookup returns the value of the entry name and whether the tag has such an entry
+func (tag ModelTag) Lookup(name string) (string, bool) {
+	for _, entry := range tag {
+		if entry.Name == name {
+			return entry.Value, true
+		}
+	}
+	return "", false
+}
+
+// Names returns the names of all the entries
+func (tag ModelTag) Names() []string {
+	var names []string
+	for _, entry := range tag {
+		names = append(names, entry.Name)
+	}
+	return names
+}
+
+// parseTagLiteral parses the tag of a struct field as it is written in the go source, with its
+// enclosing back quotes or double quotes
+func parseTagLiteral(lit string) (ModelTag, error) {
+	tag, err := strconv.Unquote(lit)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintln("Invalid struct tag", lit))
+	}
+	return ParseModelTag(tag)
+}
+
+// ParseModelTag parses the content of a model struct tag following the syntax described above
+func ParseModelTag(tag string) (ModelTag, error) {
+	var entries ModelTag
+	seen := make(map[string]bool)
+	i := 0
+	for {
+		// Skip the separator and any empty entries
+		for i < len(tag) && (tag[i] == ',' || isTagSpace(tag[i])) {
+			i++
+		}
+		if i == len(tag) {
+			return entries, nil
+		}
+		name := tagNamePattern.FindString(tag[i:])
+		if name == "" {
+			return nil, tagError(tag, i, "Expected a tag name")
+		}
+		if seen[name] {
+			return nil, tagError(tag, i, "Duplicate tag "+name)
+		}
+		seen[name] = true
+		i += len(name)
+		for i < len(tag) && isTagSpace(tag[i]) {
+			i++
+		}
+		entry := TagEntry{Name: name}
+		if i < len(tag) && tag[i] == ':' {
+			value, next := parseTagValue(tag, i+1)
+			entry.Value = value
+			entry.HasValue = true
+			i = next
+		} else if i < len(tag) && tag[i] != ',' {
+			return nil, tagError(tag, i, "Expected ':' or ',' after "+name)
+		}
+		entries = append(entries, entry)
+	}
+}
+
+// parseTagValue parses the value starting at tag[start]. It returns the value and the index of
+// the comma ending it, or len(tag).
+func parseTagValue(tag string, start int) (string, int) {
+	i := start
+	for i < len(tag) && isTagSpace(tag[i]) {
+		i++
+	}
+	if i < len(tag) && tag[i] == '"' {
+		if end := quotedEnd(tag, i); end >= 0 {
+			next := end
+			for next < len(tag) && isTagSpace(tag[next]) {
+				next++
+			}
+			text, err := strconv.Unquote(tag[i:end])
+			if err == nil && (next == len(tag) || (tag[next] == ',' && isEntryStart(tag[next:]))) {
+				return text, next
+			}
+		}
+	}
+	end := i
+	for end < len(tag) && !(tag[end] == ',' && isEntryStart(tag[end:])) {
+		end++
+	}
+	return strings.TrimRight(tag[i:end], " \t"), end
+}
+
+// isEntryStart reports whether the comma at the start of rest is followed by a new entry rather
+// than by more text of the value
+func isEntryStart(rest string) bool {
+	match := tagBoundary.FindStringSubmatch(rest)
+	if match == nil {
+		return false
+	}
+	if match[2] == "" || match[3] == ":" {
+		return true
+	}
+	return tagFlags[match[2]]
+}
+
+// quotedEnd returns the index just past the double quoted string starting at tag[start], or -1
+func quotedEnd(tag string, start int) int {
+	for i := start + 1; i < len(tag); i++ {
+		switch tag[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
+		}
+	}
+	return -1
+}
+
+func isTagSpace(c byte) bool {
+	return c == ' ' || c == '\t'
+}
+
+func tagError(tag string, offset int, msg string) error {
+	context := tag[offset:]
+	if len(context) > 20 {
+		context = context[:20] + "..."
+	}
+	return errors.New(fmt.Sprintf("%s at offset %d of the tag, near %q", msg, offset, context))
+}
@@ -0,0 +1,150 @@
+package dbifgen
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseModelTag(t *testing.T) {
+	tests := []struct {
+		tag  string
+		want ModelTag
+	}{
+		{
+			tag: `DESCRIPTION: "MTU of the interface, in bytes", MIN: "64", MAX: "9000", DEFAULT: 1500`,
+			want: ModelTag{
+				{Name: "DESCRIPTION", Value: "MTU of the interface, in bytes", HasValue: true},
+				{Name: "MIN", Value: "64", HasValue: true},
+				{Name: "MAX", Value: "9000", HasValue: true},
+				{Name: "DEFAULT", Value: "1500", HasValue: true},
+			},
+		},
+		{
+			tag: `SNAPROUTE: "KEY", ACCESS:"w", MULTIPLICITY:"*", AUTOCREATE, DESCRIPTION: Vlan id, tagged or not, MIN: "1"`,
+			want: ModelTag{
+				{Name: "SNAPROUTE", Value: "KEY", HasValue: true},
+				{Name: "ACCESS", Value: "w", HasValue: true},
+				{Name: "MULTIPLICITY", Value: "*", HasValue: true},
+				{Name: "AUTOCREATE"},
+				{Name: "DESCRIPTION", Value: "Vlan id, tagged or not", HasValue: true},
+				{Name: "MIN", Value: "1", HasValue: true},
+			},
+		},
+		{
+			tag: `DESCRIPTION: Pattern "[0-9]+\.[0-9]+"`,
+			want: ModelTag{
+				{Name: "DESCRIPTION", Value: `Pattern "[0-9]+\.[0-9]+"`, HasValue: true},
+			},
+		},
+		{
+			tag: `DESCRIPTION: Cable length 5" max, DEFAULT: 5`,
+			want: ModelTag{
+				{Name: "DESCRIPTION", Value: `Cable length 5" max`, HasValue: true},
+				{Name: "DEFAULT", Value: "5", HasValue: true},
+			},
+		},
+		{
+			tag: `DESCRIPTION: The "admin" state`,
+			want: ModelTag{
+				{Name: "DESCRIPTION", Value: `The "admin" state`, HasValue: true},
+			},
+		},
+		{
+			// Starts with a quote but goes on after it
+			tag: `DESCRIPTION: "admin" state of the port, ACCELERATED`,
+			want: ModelTag{
+				{Name: "DESCRIPTION", Value: `"admin" state of the port`, HasValue: true},
+				{Name: "ACCELERATED"},
+			},
+		},
+		{
+			// Not a valid string literal
+			tag: `DESCRIPTION: "[0-9]+\.[0-9]+"`,
+			want: ModelTag{
+				{Name: "DESCRIPTION", Value: `"[0-9]+\.[0-9]+"`, HasValue: true},
+			},
+		},
+		{
+			tag: `DESCRIPTION: "5\" cable, MIN: 1" , MIN: 1`,
+			want: ModelTag{
+				{Name: "DESCRIPTION", Value: `5" cable, MIN: 1`, HasValue: true},
+				{Name: "MIN", Value: "1", HasValue: true},
+			},
+		},
+		{
+			tag: `DEFAULT: "say \"hi\" from C:\\tmp", MIN: 1`,
+			want: ModelTag{
+				{Name: "DEFAULT", Value: `say "hi" from C:\tmp`, HasValue: true},
+				{Name: "MIN", Value: "1", HasValue: true},
+			},
+		},
+		{
+			tag: `DESCRIPTION: "unterminated, MIN: 1`,
+			want: ModelTag{
+				{Name: "DESCRIPTION", Value: `"unterminated`, HasValue: true},
+				{Name: "MIN", Value: "1", HasValue: true},
+			},
+		},
+		{
+			tag: `SELECTION: "UP"/"DOWN", DEFAULT: "UP"`,
+			want: ModelTag{
+				{Name: "SELECTION", Value: `"UP"/"DOWN"`, HasValue: true},
+				{Name: "DEFAULT", Value: "UP", HasValue: true},
+			},
+		},
+		{
+			tag: `DEFAULT: "", DESCRIPTION:,`,
+			want: ModelTag{
+				{Name: "DEFAULT", Value: "", HasValue: true},
+				{Name: "DESCRIPTION", Value: "", HasValue: true},
+			},
+		},
+		{
+			tag:  ``,
+			want: nil,
+		},
+	}
+	for _, test := range tests {
+		got, err := ParseModelTag(test.tag)
+		if err != nil {
+			t.Errorf("ParseModelTag(%q) failed: %v", test.tag, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("ParseModelTag(%q) = %+v, want %+v", test.tag, got, test.want)
+		}
+	}
+}
+
+func TestParseModelTagErrors(t *testing.T) {
+	tests := []string{
+		`ACCELERATED, ACCELERATED`,
+		`DESCRIPTION: a, MIN: 1, MIN: 2`,
+		`1KEY`,
+		`ACCELERATED "x"`,
+		`: value`,
+	}
+	for _, tag := range tests {
+		if got, err := ParseModelTag(tag); err == nil {
+			t.Errorf("ParseModelTag(%q) = %+v, want an error", tag, got)
+		}
+	}
+}
+
+func TestSelections(t *testing.T) {
+	tests := map[string][]string{
+		"`SELECTION: \"UP/DOWN\"`":     {"UP", "DOWN"},
+		"`SELECTION: \"UP\"/\"DOWN\"`": {"UP", "DOWN"},
+		"`SELECTION: up(1)/down(2)`":   {"up(1)", "down(2)"},
+	}
+	for tag, want := range tests {
+		var info ObjectMembersInfo
+		if err := getSpecialTagsForAttribute(tag, &info); err != nil {
+			t.Errorf("%s failed: %v", tag, err)
+			continue
+		}
+		if !reflect.DeepEqual(info.Selections, want) {
+			t.Errorf("Selections of %s = %q, want %q", tag, info.Selections, want)
+		}
+	}
+}
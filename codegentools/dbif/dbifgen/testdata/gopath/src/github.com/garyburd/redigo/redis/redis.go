@@ -0,0 +1,19 @@
+// Package redis declares the part of the redigo client the generated code uses
+package redis
+
+type Conn interface {
+	Do(cmd string, args ...interface{}) (interface{}, error)
+}
+
+type Args []interface{}
+
+func (a Args) Add(v ...interface{}) Args  { return append(a, v...) }
+func (a Args) AddFlat(v interface{}) Args { return append(a, v) }
+
+func Values(r interface{}, e error) ([]interface{}, error) { return nil, e }
+func String(r interface{}, e error) (string, error)        { return "", e }
+func Strings(r interface{}, e error) ([]string, error)     { return nil, e }
+func Int(r interface{}, e error) (int, error)              { return 0, e }
+func Int64(r interface{}, e error) (int64, error)          { return 0, e }
+func Uint64(r interface{}, e error) (uint64, error)        { return 0, e }
+func ScanStruct(src []interface{}, dest interface{}) error { return nil }
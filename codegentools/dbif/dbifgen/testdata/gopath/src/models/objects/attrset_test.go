@@ -0,0 +1,118 @@
+package objects
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestAttrSet(t *testing.T) {
+	attrs := Port{}.NewAttrSet(PortAttrMtu, PortAttrName)
+	if !attrs.Has(PortAttrName) || !attrs.Has(PortAttrMtu) || attrs.Has(PortAttrDescr) || attrs.Has(PortAttrSpeed) {
+		t.Errorf("NewAttrSet(PortAttrMtu, PortAttrName) = %v", attrs)
+	}
+	attrs.Add(PortAttrSpeed)
+	// Ids the object does not have are ignored
+	attrs.Add(-1)
+	attrs.Add(AttrId(len(attrNamesPort)))
+	if attrs.Has(-1) || attrs.Has(AttrId(len(attrNamesPort))) {
+		t.Errorf("Set holds ids Port does not have: %v", attrs)
+	}
+	if got, want := attrs.Names(), []string{"Name", "Mtu", "Speed"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Names = %v, want %v", got, want)
+	}
+	if attrs.Len() != 3 {
+		t.Errorf("Len = %d, want 3", attrs.Len())
+	}
+	if got, want := attrs.String(), "{Name, Mtu, Speed}"; got != want {
+		t.Errorf("String = %q, want %q", got, want)
+	}
+	if got, want := fmt.Sprint(Port{}.NewAttrSet()), "{}"; got != want {
+		t.Errorf("Empty set is printed %q, want %q", got, want)
+	}
+	if got, want := attrs.Bools(), []bool{true, false, true, true}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Bools = %v, want %v", got, want)
+	}
+}
+
+func TestAttrSetLarge(t *testing.T) {
+	var names []string
+	for i := 0; i < 130; i++ {
+		names = append(names, fmt.Sprint("A", i))
+	}
+	attrs := newAttrSet(names)
+	for _, id := range []AttrId{0, 63, 64, 129} {
+		attrs.Add(id)
+	}
+	if got, want := attrs.Names(), []string{"A0", "A63", "A64", "A129"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Names = %v, want %v", got, want)
+	}
+	bools := attrs.Bools()
+	if len(bools) != 130 || !bools[64] || bools[65] || !bools[129] {
+		t.Errorf("Bools = %v", bools)
+	}
+}
+
+// The attributes of an embedded structure take the place of the embedded field
+func TestEmbeddedAttrIds(t *testing.T) {
+	want := []string{"Name", "Descr", "Mtu", "Speed"}
+	if !reflect.DeepEqual(attrNamesPort, want) {
+		t.Errorf("attrNamesPort = %v, want %v", attrNamesPort, want)
+	}
+	ids := []AttrId{PortAttrName, PortAttrDescr, PortAttrMtu, PortAttrSpeed}
+	for i, id := range ids {
+		if id != AttrId(i) {
+			t.Errorf("Id of %s is %d, want %d", want[i], id, i)
+		}
+	}
+	typ := reflect.TypeOf(Port{})
+	for i, index := range attrFields(typ) {
+		if name := typ.FieldByIndex(index).Name; name != want[i] {
+			t.Errorf("attrFields walks %s as attribute %d, want %s", name, i, want[i])
+		}
+	}
+}
+
+func TestAttrsVariants(t *testing.T) {
+	obj := Port{Name: "p", PortCommon: PortCommon{Descr: "new", Mtu: 9000}, Speed: 100}
+	dbObj := Port{Name: "p", PortCommon: PortCommon{Descr: "old", Mtu: 1500}, Speed: 10}
+
+	attrs, err := obj.CompareObjectsAndDiffAttrs(map[string]bool{"Mtu": true, "Speed": true}, dbObj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := attrs.Names(), []string{"Mtu", "Speed"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("CompareObjectsAndDiffAttrs = %v, want %v", got, want)
+	}
+	bools, _ := obj.CompareObjectsAndDiff(map[string]bool{"Mtu": true, "Speed": true}, dbObj)
+	if !reflect.DeepEqual(bools, attrs.Bools()) {
+		t.Errorf("CompareObjectsAndDiff = %v, want %v", bools, attrs.Bools())
+	}
+
+	merged, err := obj.MergeDbAndConfigObjAttrs(dbObj, obj.NewAttrSet(PortAttrMtu))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (Port{Name: "p", PortCommon: PortCommon{Descr: "old", Mtu: 9000}, Speed: 10}); merged != want {
+		t.Errorf("MergeDbAndConfigObjAttrs = %+v, want %+v", merged, want)
+	}
+
+	merged, attrs, err = obj.MergeDbAndConfigObjForPatchUpdateAttrs(dbObj, []PatchOpInfo{{Op: "replace", Path: "/Descr", Value: `"patched"`}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (Port{Name: "p", PortCommon: PortCommon{Descr: "patched", Mtu: 1500}, Speed: 10}); merged != want {
+		t.Errorf("MergeDbAndConfigObjForPatchUpdateAttrs = %+v, want %+v", merged, want)
+	}
+	if got, want := attrs.Names(), []string{"Descr"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("MergeDbAndConfigObjForPatchUpdateAttrs changes %v, want %v", got, want)
+	}
+
+	var conn recordingConn
+	if err := obj.UpdateObjectInDbAttrs(dbObj, obj.NewAttrSet(PortAttrDescr, PortAttrSpeed), &conn); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := conn.cmds, []string{"HMSET"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("UpdateObjectInDbAttrs runs %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,13 @@
+package objects
+
+import "testing"
+
+func TestEscapedDefault(t *testing.T) {
+	obj, err := Banner{}.UnmarshalObject(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `say "hi" from C:\tmp`; obj.(Banner).Text != want {
+		t.Errorf("Default of Text is %q, want %q", obj.(Banner).Text, want)
+	}
+}
@@ -0,0 +1,102 @@
+package objects
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// newDiff returns a Diff with every attribute set, sharing nothing with the other ones returned
+func newDiff() Diff {
+	value := int32(7)
+	obj := Diff{
+		Name:    "d",
+		Count32: 1 << 20,
+		Count64: 1 << 40,
+		Vid:     10,
+		Vids:    []VlanId{10, 20},
+		Names:   []string{"a", "b"},
+		Members: []Member{{Name: "m", Idx: 1}},
+		Ptr:     &Member{Name: "p", Idx: 2},
+		Labels:  map[string]string{"k": "v"},
+		Groups:  map[string][]int32{"g": {1, 2}},
+		Flags:   [4]bool{true},
+		Slots:   [2][]string{{"s"}},
+		Tree:    Node{Value: &value, Children: []Node{{Children: []Node{{}}}}},
+		Hold:    time.Second,
+		Since:   time.Unix(1000, 0),
+	}
+	obj.Opts.Fast = true
+	obj.Opts.Peers = []string{"x"}
+	return obj
+}
+
+func TestDiffChanged(t *testing.T) {
+	for _, test := range []struct {
+		attr   string
+		change func(obj *Diff)
+	}{
+		{"Count32", func(obj *Diff) { obj.Count32 = 1<<20 + 1<<17 }},
+		{"Count64", func(obj *Diff) { obj.Count64 = 1<<40 + 1<<33 }},
+		{"Vid", func(obj *Diff) { obj.Vid = 11 }},
+		{"Vids", func(obj *Diff) { obj.Vids = []VlanId{10} }},
+		{"Names", func(obj *Diff) { obj.Names[1] = "c" }},
+		{"Members", func(obj *Diff) { obj.Members[0].Idx = 2 }},
+		{"Ptr", func(obj *Diff) { obj.Ptr.Name = "q" }},
+		{"Ptr", func(obj *Diff) { obj.Ptr = nil }},
+		{"Labels", func(obj *Diff) { obj.Labels["k"] = "w" }},
+		{"Labels", func(obj *Diff) { obj.Labels = map[string]string{"l": "v"} }},
+		{"Groups", func(obj *Diff) { obj.Groups["g"] = []int32{1} }},
+		{"Flags", func(obj *Diff) { obj.Flags[3] = true }},
+		{"Slots", func(obj *Diff) { obj.Slots[1] = []string{"t"} }},
+		{"Tree", func(obj *Diff) { *obj.Tree.Value = 8 }},
+		{"Tree", func(obj *Diff) { obj.Tree.Children[0].Children = append(obj.Tree.Children[0].Children, Node{}) }},
+		{"Opts", func(obj *Diff) { obj.Opts.Peers = nil }},
+		{"Hold", func(obj *Diff) { obj.Hold = time.Minute }},
+		{"Since", func(obj *Diff) { obj.Since = obj.Since.Add(time.Nanosecond) }},
+	} {
+		a, b := newDiff(), newDiff()
+		test.change(&b)
+		attrs, err := a.CompareObjectsAndDiffAttrs(map[string]bool{test.attr: true, "Name": true}, b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := attrs.Names(); !reflect.DeepEqual(got, []string{test.attr}) {
+			t.Errorf("CompareObjectsAndDiffAttrs after changing %s = %v", test.attr, got)
+		}
+		attrs, err = a.CompareObjectDefaultAndDiffAttrs(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := attrs.Names(); !reflect.DeepEqual(got, []string{test.attr}) {
+			t.Errorf("CompareObjectDefaultAndDiffAttrs after changing %s = %v", test.attr, got)
+		}
+	}
+}
+
+func TestDiffUnchanged(t *testing.T) {
+	a, b := newDiff(), newDiff()
+	all := make(map[string]bool)
+	for _, name := range attrNamesDiff {
+		all[name] = true
+	}
+	attrs, err := a.CompareObjectsAndDiffAttrs(all, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if attrs.Len() != 0 {
+		t.Errorf("Objects with equal content differ in %v", attrs)
+	}
+
+	// Nil and empty slices and maps are equal
+	a, b = Diff{}, Diff{Vids: []VlanId{}, Names: []string{}, Members: []Member{}, Labels: map[string]string{}, Groups: map[string][]int32{}}
+	b.Opts.Peers = []string{}
+	b.Tree.Children = []Node{}
+	attrs, err = a.CompareObjectsAndDiffAttrs(all, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if attrs.Len() != 0 {
+		t.Errorf("Nil and empty values differ in %v", attrs)
+	}
+}
@@ -0,0 +1,60 @@
+package objects
+
+import (
+	"reflect"
+	"testing"
+)
+
+// recordingConn is a redis connection that records the commands it is given
+type recordingConn struct {
+	cmds []string
+}
+
+func (conn *recordingConn) Do(cmd string, args ...interface{}) (interface{}, error) {
+	conn.cmds = append(conn.cmds, cmd)
+	return nil, nil
+}
+
+func TestMultiNameAttrs(t *testing.T) {
+	want := []string{"Name", "A", "B", "Descr"}
+	if !reflect.DeepEqual(attrNamesEdge, want) {
+		t.Errorf("attrNamesEdge = %v, want %v", attrNamesEdge, want)
+	}
+	if EdgeAttrB != 2 || EdgeAttrDescr != 3 {
+		t.Errorf("EdgeAttrB = %d, EdgeAttrDescr = %d, want 2 and 3", EdgeAttrB, EdgeAttrDescr)
+	}
+	if n := len(attrFields(reflect.TypeOf(Edge{}))); n != len(attrNamesEdge) {
+		t.Errorf("attrFields walks %d fields of Edge, want %d", n, len(attrNamesEdge))
+	}
+}
+
+func TestMultiNameDiff(t *testing.T) {
+	a := Edge{Name: "e", A: 1, B: 2, Descr: "x"}
+	b := Edge{Name: "e", A: 1, B: 3, Descr: "x"}
+	attrs, err := a.CompareObjectsAndDiffAttrs(map[string]bool{"A": true, "B": true}, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := attrs.Names(); !reflect.DeepEqual(got, []string{"B"}) {
+		t.Errorf("CompareObjectsAndDiffAttrs = %v, want [B]", got)
+	}
+	bools, _ := a.CompareObjectsAndDiff(map[string]bool{"B": true}, b)
+	if !reflect.DeepEqual(bools, []bool{false, false, true, false}) {
+		t.Errorf("CompareObjectsAndDiff = %v, want B set", bools)
+	}
+}
+
+func TestMultiNameUpdate(t *testing.T) {
+	a := Edge{Name: "e", A: 1, B: 2, Descr: "x"}
+	var conn recordingConn
+	if err := a.UpdateObjectInDbAttrs(a, a.NewAttrSet(EdgeAttrB, EdgeAttrDescr), &conn); err != nil {
+		t.Fatal(err)
+	}
+	merged, err := a.MergeDbAndConfigObjAttrs(Edge{Name: "e", A: 5, B: 6, Descr: "y"}, a.NewAttrSet(EdgeAttrB))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (Edge{Name: "e", A: 5, B: 2, Descr: "y"}); merged != want {
+		t.Errorf("MergeDbAndConfigObjAttrs = %+v, want %+v", merged, want)
+	}
+}
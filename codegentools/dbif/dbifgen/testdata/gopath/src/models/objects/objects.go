@@ -0,0 +1,17 @@
+package objects
+
+// The declarations of the hand coded objects.go the generated code depends on
+
+type ConfigObj interface {
+	GetKey() string
+}
+
+type baseObj struct{}
+
+type PatchOpInfo struct {
+	Op    string `json:"op"`
+	Path  string `json:"path"`
+	Value string `json:"value"`
+	// Optional, move and copy need it
+	From string `json:"from"`
+}
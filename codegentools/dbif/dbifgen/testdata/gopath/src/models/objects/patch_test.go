@@ -0,0 +1,37 @@
+package objects
+
+import (
+	"reflect"
+	"testing"
+)
+
+// The from pointer of move and copy is the From member of PatchOpInfo
+func TestPatchOpInfoFrom(t *testing.T) {
+	obj := Port{Name: "p", PortCommon: PortCommon{Descr: "d", Mtu: 1500}, Speed: 10}
+	_, _, err := obj.MergeDbAndConfigObjForPatchUpdateAttrs(obj, []PatchOpInfo{
+		{Op: "copy", From: "/Mtu", Path: "/Speed"},
+		{Op: "move", From: "/Descr", Path: "/Name"},
+	})
+	if patchErr, ok := err.(*PatchError); !ok || patchErr.Index != 1 {
+		t.Errorf("Move to the key returned %v, want an error at operation 1", err)
+	}
+
+	merged, attrs, err := obj.MergeDbAndConfigObjForPatchUpdateAttrs(obj, []PatchOpInfo{{Op: "copy", From: "/Mtu", Path: "/Speed"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (Port{Name: "p", PortCommon: PortCommon{Descr: "d", Mtu: 1500}, Speed: 1500}); merged != want {
+		t.Errorf("Patched object is %+v, want %+v", merged, want)
+	}
+	if got, want := attrs.Names(), []string{"Speed"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Patch modified %v, want %v", got, want)
+	}
+
+	_, bools, err := obj.MergeDbAndConfigObjForPatchUpdate(obj, []PatchOpInfo{{Op: "move", From: "/Mtu", Path: "/Speed"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []bool{false, false, true, true}; !reflect.DeepEqual(bools, want) {
+		t.Errorf("Move modified %v, want %v", bools, want)
+	}
+}
@@ -0,0 +1,69 @@
+package objects
+
+import "time"
+
+// Edge declares two of its members at once
+type Edge struct {
+	baseObj
+	Name  string `SNAPROUTE: "KEY", DESCRIPTION: Edge name`
+	A, B  int32  `DESCRIPTION: Ends of the edge`
+	Descr string `DESCRIPTION: Description`
+}
+
+// Banner has a default that needs escapes in go source
+type Banner struct {
+	baseObj
+	Name string `SNAPROUTE: "KEY", DESCRIPTION: Banner name`
+	Text string `DESCRIPTION: Banner text, DEFAULT: "say \"hi\" from C:\\tmp"`
+}
+
+// Diff has members of all the kinds of types CompareObjectsAndDiff compares
+type Diff struct {
+	baseObj
+	Name    string             `SNAPROUTE: "KEY", DESCRIPTION: Name, AUTOCREATE`
+	Count32 uint32             `DESCRIPTION: Counter`
+	Count64 uint64             `DESCRIPTION: Counter`
+	Vid     VlanId             `DESCRIPTION: Vlan`
+	Vids    []VlanId           `DESCRIPTION: Vlans`
+	Names   []string           `DESCRIPTION: Names`
+	Members []Member           `DESCRIPTION: Members`
+	Ptr     *Member            `DESCRIPTION: Pointer`
+	Labels  map[string]string  `DESCRIPTION: Labels`
+	Groups  map[string][]int32 `DESCRIPTION: Groups`
+	Flags   [4]bool            `DESCRIPTION: Flags`
+	Slots   [2][]string        `DESCRIPTION: Slots`
+	Tree    Node               `DESCRIPTION: Tree`
+	Opts    struct {
+		Fast  bool
+		Peers []string
+	} `DESCRIPTION: Options`
+	Hold  time.Duration `DESCRIPTION: Hold time`
+	Since time.Time     `DESCRIPTION: Start time`
+}
+
+type VlanId uint16
+
+type Member struct {
+	Name string
+	Idx  int32
+}
+
+// Node refers to itself
+type Node struct {
+	Value    *int32
+	Children []Node
+}
+
+// PortCommon is embedded by Port
+type PortCommon struct {
+	Descr string `DESCRIPTION: Description`
+	Mtu   int32  `DESCRIPTION: MTU`
+}
+
+// Port has the attributes of PortCommon between its own
+type Port struct {
+	baseObj
+	Name string `SNAPROUTE: "KEY", DESCRIPTION: Name`
+	PortCommon
+	Speed uint32 `DESCRIPTION: Speed`
+}
@@ -0,0 +1,11 @@
+// Package alphaNumSort declares the part of utils/alphaNumSort the generated code uses
+package alphaNumSort
+
+func Compare(a, b string) int {
+	if a < b {
+		return -1
+	} else if a > b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,8 @@
+package objects
+
+// PatchOpInfo as it is declared in the objects package
+type PatchOpInfo struct {
+	Op    string `json:"op"`
+	Path  string `json:"path"`
+	Value string `json:"value"`
+}
@@ -0,0 +1,554 @@
+package objects
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// jsonValue decodes the json value s, as the patch code does
+func jsonValue(t *testing.T, s string) interface{} {
+	value, err := decodeJSONValue([]byte(s))
+	if err != nil {
+		t.Fatalf("Invalid json %s: %v", s, err)
+	}
+	return value
+}
+
+// memberNames returns the names of the members of the json objects docs
+func memberNames(docs ...interface{}) []string {
+	var names []string
+	for _, doc := range docs {
+		members, _ := doc.(map[string]interface{})
+		for name := range members {
+			if !isAttrName(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// TestRFC6902 applies the examples of appendix A of RFC 6902 and a few more to generic json
+// documents. The members of the documents stand for the attributes of an object. A want of ""
+// is a patch that fails.
+func TestRFC6902(t *testing.T) {
+	tests := []struct {
+		name  string
+		doc   string
+		patch string
+		want  string
+	}{
+		{
+			name:  "A.1 adding an object member",
+			doc:   `{"foo": "bar"}`,
+			patch: `[{"op": "add", "path": "/baz", "value": "qux"}]`,
+			want:  `{"baz": "qux", "foo": "bar"}`,
+		},
+		{
+			name:  "A.2 adding an array element",
+			doc:   `{"foo": ["bar", "baz"]}`,
+			patch: `[{"op": "add", "path": "/foo/1", "value": "qux"}]`,
+			want:  `{"foo": ["bar", "qux", "baz"]}`,
+		},
+		{
+			name:  "A.3 removing an object member",
+			doc:   `{"baz": "qux", "foo": "bar"}`,
+			patch: `[{"op": "remove", "path": "/baz"}]`,
+			want:  `{"foo": "bar"}`,
+		},
+		{
+			name:  "A.4 removing an array element",
+			doc:   `{"foo": ["bar", "qux", "baz"]}`,
+			patch: `[{"op": "remove", "path": "/foo/1"}]`,
+			want:  `{"foo": ["bar", "baz"]}`,
+		},
+		{
+			name:  "A.5 replacing a value",
+			doc:   `{"baz": "qux", "foo": "bar"}`,
+			patch: `[{"op": "replace", "path": "/baz", "value": "boo"}]`,
+			want:  `{"baz": "boo", "foo": "bar"}`,
+		},
+		{
+			name:  "A.6 moving a value",
+			doc:   `{"foo": {"bar": "baz", "waldo": "fred"}, "qux": {"corge": "grault"}}`,
+			patch: `[{"op": "move", "from": "/foo/waldo", "path": "/qux/thud"}]`,
+			want:  `{"foo": {"bar": "baz"}, "qux": {"corge": "grault", "thud": "fred"}}`,
+		},
+		{
+			name:  "A.7 moving an array element",
+			doc:   `{"foo": ["all", "grass", "cows", "eat"]}`,
+			patch: `[{"op": "move", "from": "/foo/1", "path": "/foo/3"}]`,
+			want:  `{"foo": ["all", "cows", "eat", "grass"]}`,
+		},
+		{
+			name: "A.8 testing a value: success",
+			doc:  `{"baz": "qux", "foo": ["a", 2, "c"]}`,
+			patch: `[{"op": "test", "path": "/baz", "value": "qux"},
+				{"op": "test", "path": "/foo/1", "value": 2}]`,
+			want: `{"baz": "qux", "foo": ["a", 2, "c"]}`,
+		},
+		{
+			name:  "A.9 testing a value: error",
+			doc:   `{"baz": "qux"}`,
+			patch: `[{"op": "test", "path": "/baz", "value": "bar"}]`,
+		},
+		{
+			name:  "A.10 adding a nested member object",
+			doc:   `{"foo": "bar"}`,
+			patch: `[{"op": "add", "path": "/child", "value": {"grandchild": {}}}]`,
+			want:  `{"foo": "bar", "child": {"grandchild": {}}}`,
+		},
+		{
+			name:  "A.11 ignoring unrecognized elements",
+			doc:   `{"foo": "bar"}`,
+			patch: `[{"op": "add", "path": "/baz", "value": "qux", "xyz": 123}]`,
+			want:  `{"foo": "bar", "baz": "qux"}`,
+		},
+		{
+			name:  "A.12 adding to a nonexistent target",
+			doc:   `{"foo": "bar"}`,
+			patch: `[{"op": "add", "path": "/baz/bat", "value": "qux"}]`,
+		},
+		// A.13, an operation with two op members, is left to encoding/json, which keeps the last
+		{
+			name:  "A.14 ~ escape ordering",
+			doc:   `{"/": 9, "~1": 10}`,
+			patch: `[{"op": "test", "path": "/~01", "value": 10}]`,
+			want:  `{"/": 9, "~1": 10}`,
+		},
+		{
+			name:  "A.15 comparing strings and numbers",
+			doc:   `{"/": 9, "~1": 10}`,
+			patch: `[{"op": "test", "path": "/~01", "value": "10"}]`,
+		},
+		{
+			name:  "A.16 adding an array value",
+			doc:   `{"foo": ["bar"]}`,
+			patch: `[{"op": "add", "path": "/foo/-", "value": ["abc", "def"]}]`,
+			want:  `{"foo": ["bar", ["abc", "def"]]}`,
+		},
+		{
+			name:  "appending to an empty array",
+			doc:   `{"foo": []}`,
+			patch: `[{"op": "add", "path": "/foo/-", "value": 1}, {"op": "add", "path": "/foo/-", "value": 2}]`,
+			want:  `{"foo": [1, 2]}`,
+		},
+		{
+			name:  "adding past the end of an array",
+			doc:   `{"foo": ["bar"]}`,
+			patch: `[{"op": "add", "path": "/foo/2", "value": "baz"}]`,
+		},
+		{
+			name:  "removing the element past the end of an array",
+			doc:   `{"foo": ["bar"]}`,
+			patch: `[{"op": "remove", "path": "/foo/-"}]`,
+		},
+		{
+			name:  "array index with a leading zero",
+			doc:   `{"foo": ["bar", "baz"]}`,
+			patch: `[{"op": "replace", "path": "/foo/01", "value": "qux"}]`,
+		},
+		{
+			name:  "moving a value into one of its children",
+			doc:   `{"foo": {"bar": 1}}`,
+			patch: `[{"op": "move", "from": "/foo", "path": "/foo/bar/baz"}]`,
+		},
+		{
+			name:  "moving a value to itself",
+			doc:   `{"foo": {"bar": 1}}`,
+			patch: `[{"op": "move", "from": "/foo", "path": "/foo"}]`,
+			want:  `{"foo": {"bar": 1}}`,
+		},
+		{
+			name:  "copying a value",
+			doc:   `{"foo": {"bar": [1]}, "baz": {}}`,
+			patch: `[{"op": "copy", "from": "/foo/bar", "path": "/baz/bar"}, {"op": "add", "path": "/foo/bar/-", "value": 2}]`,
+			want:  `{"foo": {"bar": [1, 2]}, "baz": {"bar": [1]}}`,
+		},
+		{
+			name:  "testing numbers of other forms",
+			doc:   `{"foo": 10, "bar": [1.5]}`,
+			patch: `[{"op": "test", "path": "/foo", "value": 1e1}, {"op": "test", "path": "/foo", "value": 10.0}, {"op": "test", "path": "/bar", "value": [15e-1]}]`,
+			want:  `{"foo": 10, "bar": [1.5]}`,
+		},
+		{
+			name:  "testing 64 bit integers",
+			doc:   `{"foo": 9007199254740993}`,
+			patch: `[{"op": "test", "path": "/foo", "value": 9007199254740992}]`,
+		},
+		{
+			name:  "testing objects",
+			doc:   `{"foo": {"a": 1, "b": [true, null]}}`,
+			patch: `[{"op": "test", "path": "/foo", "value": {"b": [true, null], "a": 1.0}}]`,
+			want:  `{"foo": {"a": 1, "b": [true, null]}}`,
+		},
+		{
+			name:  "testing an object with a missing member",
+			doc:   `{"foo": {"a": 1, "b": null}}`,
+			patch: `[{"op": "test", "path": "/foo", "value": {"a": 1}}]`,
+		},
+		{
+			name:  "replacing a nonexistent value",
+			doc:   `{"foo": {}}`,
+			patch: `[{"op": "replace", "path": "/foo/bar", "value": 1}]`,
+		},
+		{
+			name:  "unknown operation",
+			doc:   `{"foo": 1}`,
+			patch: `[{"op": "increment", "path": "/foo", "value": 1}]`,
+		},
+		{
+			name:  "missing value",
+			doc:   `{"foo": 1}`,
+			patch: `[{"op": "replace", "path": "/foo"}]`,
+		},
+		{
+			name:  "invalid pointer",
+			doc:   `{"foo": 1}`,
+			patch: `[{"op": "replace", "path": "/foo~2", "value": 1}]`,
+		},
+	}
+	for _, test := range tests {
+		ops, err := parseJSONPatch("Doc", []byte(test.patch))
+		if err != nil {
+			t.Errorf("%s: parseJSONPatch failed: %v", test.name, err)
+			continue
+		}
+		doc := jsonValue(t, test.doc)
+		var want interface{}
+		if test.want != "" {
+			want = jsonValue(t, test.want)
+		}
+		names := memberNames(doc, want)
+		for _, op := range ops {
+			if doc, _, err = applyPatchOp(doc, names, nil, op); err != nil {
+				break
+			}
+		}
+		switch {
+		case test.want == "" && err == nil:
+			t.Errorf("%s: patch succeeded, want an error", test.name)
+		case test.want != "" && err != nil:
+			t.Errorf("%s: patch failed: %v", test.name, err)
+		case test.want != "" && !jsonEqual(doc, want):
+			got, _ := json.Marshal(doc)
+			t.Errorf("%s: patched document is %s, want %s", test.name, got, test.want)
+		}
+	}
+}
+
+// TestRFC7386 merges the examples of appendix A of RFC 7386
+func TestRFC7386(t *testing.T) {
+	tests := []struct {
+		target, patch, want string
+	}{
+		{`{"a": "b"}`, `{"a": "c"}`, `{"a": "c"}`},
+		{`{"a": "b"}`, `{"b": "c"}`, `{"a": "b", "b": "c"}`},
+		{`{"a": "b"}`, `{"a": null}`, `{}`},
+		{`{"a": "b", "b": "c"}`, `{"a": null}`, `{"b": "c"}`},
+		{`{"a": ["b"]}`, `{"a": "c"}`, `{"a": "c"}`},
+		{`{"a": "c"}`, `{"a": ["b"]}`, `{"a": ["b"]}`},
+		{`{"a": {"b": "c"}}`, `{"a": {"b": "d", "c": null}}`, `{"a": {"b": "d"}}`},
+		{`{"a": [{"b": "c"}]}`, `{"a": [1]}`, `{"a": [1]}`},
+		{`["a", "b"]`, `["c", "d"]`, `["c", "d"]`},
+		{`{"a": "b"}`, `["c"]`, `["c"]`},
+		{`{"a": "foo"}`, `null`, `null`},
+		{`{"a": "foo"}`, `"bar"`, `"bar"`},
+		{`{"e": null}`, `{"a": 1}`, `{"e": null, "a": 1}`},
+		{`[1, 2]`, `{"a": "b", "c": null}`, `{"a": "b"}`},
+		{`{}`, `{"a": {"bb": {"ccc": null}}}`, `{"a": {"bb": {}}}`},
+	}
+	for _, test := range tests {
+		got := mergePatchValue(jsonValue(t, test.target), jsonValue(t, test.patch))
+		if !jsonEqual(got, jsonValue(t, test.want)) {
+			data, _ := json.Marshal(got)
+			t.Errorf("Merging %s into %s gives %s, want %s", test.patch, test.target, data, test.want)
+		}
+	}
+}
+
+type PortInfo struct {
+	Speed  int32
+	Duplex string
+}
+
+// Port is an object with the key attribute Name and a default Mtu of 1500
+type Port struct {
+	Name   string
+	Mtu    int32
+	Vlans  []uint16
+	Info   PortInfo
+	Weight float64
+}
+
+var attrNamesPort = []string{"Name", "Mtu", "Vlans", "Info", "Weight"}
+var keyNamesPort = []string{"Name"}
+var defaultPort = Port{Mtu: 1500, Info: PortInfo{Duplex: "full"}}
+
+func testPort() Port {
+	return Port{Name: "p1", Mtu: 9000, Vlans: []uint16{10, 20}, Info: PortInfo{Speed: 100, Duplex: "half"}, Weight: 0.5}
+}
+
+func TestApplyJSONPatch(t *testing.T) {
+	tests := []struct {
+		name  string
+		patch string
+		want  func(*Port)
+		attrs []string
+		index int
+	}{
+		{
+			name:  "replace",
+			patch: `[{"op": "replace", "path": "/Mtu", "value": 1400}]`,
+			want:  func(p *Port) { p.Mtu = 1400 },
+			attrs: []string{"Mtu"},
+		},
+		{
+			name:  "remove resets an attribute to its default",
+			patch: `[{"op": "remove", "path": "/Mtu"}, {"op": "remove", "path": "/Info"}]`,
+			want:  func(p *Port) { p.Mtu = 1500; p.Info = PortInfo{Duplex: "full"} },
+			attrs: []string{"Mtu", "Info"},
+		},
+		{
+			name:  "remove resets an array attribute to an empty array",
+			patch: `[{"op": "remove", "path": "/Vlans"}, {"op": "add", "path": "/Vlans/-", "value": 30}]`,
+			want:  func(p *Port) { p.Vlans = []uint16{30} },
+			attrs: []string{"Vlans"},
+		},
+		{
+			name:  "remove of a struct member gives it its zero value",
+			patch: `[{"op": "remove", "path": "/Info/Speed"}]`,
+			want:  func(p *Port) { p.Info.Speed = 0 },
+			attrs: []string{"Info"},
+		},
+		{
+			name:  "array elements",
+			patch: `[{"op": "add", "path": "/Vlans/-", "value": 30}, {"op": "add", "path": "/Vlans/0", "value": 5}, {"op": "remove", "path": "/Vlans/1"}]`,
+			want:  func(p *Port) { p.Vlans = []uint16{5, 20, 30} },
+			attrs: []string{"Vlans"},
+		},
+		{
+			name:  "move resets the attribute it moves from",
+			patch: `[{"op": "move", "from": "/Mtu", "path": "/Info/Speed"}]`,
+			want:  func(p *Port) { p.Mtu = 1500; p.Info.Speed = 9000 },
+			attrs: []string{"Mtu", "Info"},
+		},
+		{
+			name:  "copy",
+			patch: `[{"op": "copy", "from": "/Info/Speed", "path": "/Mtu"}]`,
+			want:  func(p *Port) { p.Mtu = 100 },
+			attrs: []string{"Mtu"},
+		},
+		{
+			name:  "test does not modify attributes",
+			patch: `[{"op": "test", "path": "/Name", "value": "p1"}, {"op": "test", "path": "/Mtu", "value": 9000.0}]`,
+			want:  func(p *Port) {},
+		},
+		{
+			name:  "test failure fails the whole patch",
+			patch: `[{"op": "replace", "path": "/Mtu", "value": 1400}, {"op": "test", "path": "/Mtu", "value": 9000}]`,
+			index: 1,
+		},
+		{
+			name:  "key attributes can not be changed",
+			patch: `[{"op": "replace", "path": "/Name", "value": "p2"}]`,
+		},
+		{
+			name:  "key attributes can not be removed",
+			patch: `[{"op": "remove", "path": "/Name"}]`,
+		},
+		{
+			name:  "key attributes can be replaced by their value",
+			patch: `[{"op": "replace", "path": "/Name", "value": "p1"}]`,
+			want:  func(p *Port) {},
+			attrs: []string{"Name"},
+		},
+		{
+			name:  "unknown attribute",
+			patch: `[{"op": "add", "path": "/Speed", "value": 1}]`,
+		},
+		{
+			name:  "invalid value",
+			patch: `[{"op": "replace", "path": "/Mtu", "value": "high"}]`,
+			index: -1,
+		},
+		{
+			name:  "value out of range",
+			patch: `[{"op": "add", "path": "/Vlans/-", "value": 65536}]`,
+			index: -1,
+		},
+		{
+			name:  "patch of the whole object",
+			patch: `[{"op": "replace", "path": "", "value": [1]}]`,
+			index: -1,
+		},
+		{
+			name:  "attribute name paths",
+			patch: `[{"op": "add", "path": "Vlans", "value": [30, 40]}, {"op": "remove", "path": "Vlans", "value": [10, 40, 50]}, {"op": "replace", "path": "Mtu", "value": 1400}]`,
+			want:  func(p *Port) { p.Vlans = []uint16{20, 30}; p.Mtu = 1400 },
+			attrs: []string{"Mtu", "Vlans"},
+		},
+		{
+			name:  "attribute name paths can not change keys",
+			patch: `[{"op": "replace", "path": "Name", "value": "p2"}]`,
+		},
+		{
+			name:  "attribute name add to a scalar",
+			patch: `[{"op": "add", "path": "Mtu", "value": [1]}]`,
+		},
+	}
+	for _, test := range tests {
+		ops, err := parseJSONPatch("Port", []byte(test.patch))
+		if err != nil {
+			t.Errorf("%s: parseJSONPatch failed: %v", test.name, err)
+			continue
+		}
+		src, dst := testPort(), testPort()
+		attrs, err := applyJSONPatch("Port", attrNamesPort, keyNamesPort, &src, &defaultPort, &dst, ops)
+		if !reflect.DeepEqual(src, testPort()) {
+			t.Errorf("%s: patch modified the source object", test.name)
+		}
+		if test.want == nil {
+			patchErr, ok := err.(*PatchError)
+			if !ok {
+				t.Errorf("%s: patch returned %v, want a *PatchError", test.name, err)
+			} else if patchErr.Index != test.index {
+				t.Errorf("%s: patch failed at operation %d, want %d: %v", test.name, patchErr.Index, test.index, err)
+			}
+			if !reflect.DeepEqual(dst, testPort()) {
+				t.Errorf("%s: failed patch modified the object to %+v", test.name, dst)
+			}
+			if attrs.Len() != 0 {
+				t.Errorf("%s: failed patch modified attributes %v", test.name, attrs)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: patch failed: %v", test.name, err)
+			continue
+		}
+		want := testPort()
+		test.want(&want)
+		if !reflect.DeepEqual(dst, want) {
+			t.Errorf("%s: patched object is %+v, want %+v", test.name, dst, want)
+		}
+		if got := attrs.Names(); strings.Join(got, ",") != strings.Join(test.attrs, ",") {
+			t.Errorf("%s: patch modified attributes %v, want %v", test.name, got, test.attrs)
+		}
+	}
+}
+
+func TestPatchOpsFromPatchOpInfo(t *testing.T) {
+	ops, err := patchOpsFromPatchOpInfo("Port", []PatchOpInfo{
+		{Op: "add", Path: "Vlans", Value: "[30]"},
+		{Op: "remove", Path: "/Mtu"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []JSONPatchOp{
+		{Op: "add", Path: "Vlans", Value: json.RawMessage("[30]")},
+		{Op: "remove", Path: "/Mtu"},
+	}
+	if !reflect.DeepEqual(ops, want) {
+		t.Errorf("patchOpsFromPatchOpInfo = %+v, want %+v", ops, want)
+	}
+	for _, op := range []string{"move", "copy"} {
+		_, err := patchOpsFromPatchOpInfo("Port", []PatchOpInfo{
+			{Op: "test", Path: "/Mtu", Value: "9000"},
+			{Op: op, Path: "/Mtu", Value: "/Info/Speed"},
+		})
+		if patchErr, ok := err.(*PatchError); !ok || patchErr.Index != 1 {
+			t.Errorf("patchOpsFromPatchOpInfo of %s returned %v, want an error at operation 1", op, err)
+		}
+	}
+}
+
+func TestApplyMergePatch(t *testing.T) {
+	tests := []struct {
+		name  string
+		patch string
+		want  func(*Port)
+		attrs []string
+	}{
+		{
+			name:  "merge",
+			patch: `{"Mtu": 1400, "Vlans": [30], "Info": {"Speed": 10}}`,
+			want:  func(p *Port) { p.Mtu = 1400; p.Vlans = []uint16{30}; p.Info.Speed = 10 },
+			attrs: []string{"Mtu", "Vlans", "Info"},
+		},
+		{
+			name:  "null resets an attribute to its default",
+			patch: `{"Mtu": null, "Info": null}`,
+			want:  func(p *Port) { p.Mtu = 1500; p.Info = PortInfo{Duplex: "full"} },
+			attrs: []string{"Mtu", "Info"},
+		},
+		{
+			name:  "null resets a struct member to its zero value",
+			patch: `{"Info": {"Duplex": null}}`,
+			want:  func(p *Port) { p.Info.Duplex = "" },
+			attrs: []string{"Info"},
+		},
+		{
+			name:  "equal values are not changes",
+			patch: `{"Name": "p1", "Mtu": 9000, "Vlans": [10, 20], "Info": {"Speed": 100}, "Weight": 5e-1}`,
+			want:  func(p *Port) {},
+		},
+		{
+			name:  "key attributes can not be changed",
+			patch: `{"Name": "p2"}`,
+		},
+		{
+			name:  "key attributes can not be reset",
+			patch: `{"Name": null}`,
+		},
+		{
+			name:  "unknown attribute",
+			patch: `{"Speed": 10}`,
+		},
+		{
+			name:  "invalid value",
+			patch: `{"Mtu": "high"}`,
+		},
+		{
+			name:  "not an object",
+			patch: `[{"op": "remove", "path": "/Mtu"}]`,
+		},
+		{
+			name:  "invalid document",
+			patch: `{"Mtu": }`,
+		},
+	}
+	for _, test := range tests {
+		src, dst := testPort(), testPort()
+		attrs, err := applyMergePatch("Port", attrNamesPort, keyNamesPort, &src, &defaultPort, &dst, []byte(test.patch))
+		if !reflect.DeepEqual(src, testPort()) {
+			t.Errorf("%s: merge patch modified the source object", test.name)
+		}
+		if test.want == nil {
+			if _, ok := err.(*PatchError); !ok {
+				t.Errorf("%s: merge patch returned %v, want a *PatchError", test.name, err)
+			}
+			if attrs.Len() != 0 {
+				t.Errorf("%s: failed merge patch modified attributes %v", test.name, attrs)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: merge patch failed: %v", test.name, err)
+			continue
+		}
+		want := testPort()
+		test.want(&want)
+		if !reflect.DeepEqual(dst, want) {
+			t.Errorf("%s: patched object is %+v, want %+v", test.name, dst, want)
+		}
+		if got := attrs.Names(); strings.Join(got, ",") != strings.Join(test.attrs, ",") {
+			t.Errorf("%s: merge patch modified attributes %v, want %v", test.name, got, test.attrs)
+		}
+	}
+}
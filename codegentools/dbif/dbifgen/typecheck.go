@@ -0,0 +1,275 @@
+package dbifgen
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/constant"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// checkedPackage is a model package with its type information and the errors found loading it
+type checkedPackage struct {
+	pkg  *types.Package
+	info *types.Info
+	// Errors type-checking the package, and the errors finding and parsing it
+	typeErrs []types.Error
+	loadErrs []packages.Error
+}
+
+// Information loaded for the packages that are type-checked. The dependencies are loaded from
+// source as well, so that the model packages they include are checked without the files written
+// by the generator.
+const loadMode = packages.NeedName | packages.NeedFiles | packages.NeedImports | packages.NeedDeps |
+	packages.NeedSyntax | packages.NeedTypes | packages.NeedTypesInfo
+
+// checkPackage type-checks the package in dir. The files written by the generator are left out,
+// so the package need not check cleanly, and the types that are resolved are still valid. The
+// errors are kept to explain the types and constants of members that are not resolved, see
+// typeError. The packages loaded along with it are kept too, by directory.
+func (finder *structFinder) checkPackage(dir string) *checkedPackage {
+	if checked, seen := finder.checked[dir]; seen {
+		return checked
+	}
+	cfg := &packages.Config{
+		Mode:      loadMode,
+		Dir:       dir,
+		Env:       loadEnv(dir),
+		Fset:      finder.fset,
+		ParseFile: finder.parseSource,
+	}
+	pkgs, err := packages.Load(cfg, ".")
+	if err != nil || len(pkgs) != 1 {
+		if err == nil {
+			err = errors.New(fmt.Sprintln("Directory", dir, "holds", len(pkgs), "packages"))
+		}
+		checked := &checkedPackage{loadErrs: []packages.Error{{Msg: err.Error()}}}
+		finder.checked[dir] = checked
+		return checked
+	}
+	packages.Visit(pkgs, nil, func(pkg *packages.Package) {
+		if len(pkg.GoFiles) == 0 {
+			return
+		}
+		pkgDir := filepath.Dir(pkg.GoFiles[0])
+		if _, seen := finder.checked[pkgDir]; !seen {
+			finder.checked[pkgDir] = newCheckedPackage(pkg)
+		}
+	})
+	// The directory is kept as given, go list reports it cleaned
+	checked := newCheckedPackage(pkgs[0])
+	finder.checked[dir] = checked
+	return checked
+}
+
+func newCheckedPackage(pkg *packages.Package) *checkedPackage {
+	return &checkedPackage{pkg: pkg.Types, info: pkg.TypesInfo, typeErrs: pkg.TypeErrors, loadErrs: pkg.Errors}
+}
+
+// loadEnv returns the environment the packages in dir are loaded in. The models are built in GOPATH
+// mode with the tree dir is in added to GOPATH, whatever the environment of the generator is.
+func loadEnv(dir string) []string {
+	// Later entries take precedence
+	return append(os.Environ(), "GO111MODULE=off", "GOFLAGS=", "GOPATH="+modelGopath(dir))
+}
+
+// parseSource is the parser of the packages loaded to be type-checked. The files already parsed by
+// the finder are shared, so that their expressions are found in the type information. Of the files
+// written by the generator only the package clause is kept. The function bodies of the standard
+// library are dropped, only its declarations are needed to check the models.
+func (finder *structFinder) parseSource(fset *token.FileSet, filename string, src []byte) (*ast.File, error) {
+	if isGeneratedSrcFile(filename) {
+		return parser.ParseFile(fset, filename, src, parser.PackageClauseOnly)
+	}
+	if !strings.HasPrefix(filename, filepath.Join(build.Default.GOROOT, "src")+string(filepath.Separator)) {
+		return finder.parseFile(filename)
+	}
+	f, err := parser.ParseFile(fset, filename, src, parser.SkipObjectResolution)
+	if f != nil {
+		for _, decl := range f.Decls {
+			if fn, ok := decl.(*ast.FuncDecl); ok {
+				fn.Body = nil
+			}
+		}
+	}
+	return f, err
+}
+
+// filePackage returns the type-checked package of the file f
+func (finder *structFinder) filePackage(f *ast.File) *checkedPackage {
+	return finder.checkPackage(filepath.Dir(finder.fset.File(f.Pos()).Name()))
+}
+
+// typeError returns the first error found type-checking the package between the positions pos and
+// end, or else the first error of the package, as the reason something in that range could not
+// be resolved
+func (checked *checkedPackage) typeError(pos token.Pos, end token.Pos) error {
+	for _, err := range checked.typeErrs {
+		if err.Pos >= pos && err.Pos < end {
+			return errors.New(err.Msg)
+		}
+	}
+	if len(checked.typeErrs) > 0 {
+		return errors.New(checked.typeErrs[0].Msg)
+	}
+	if len(checked.loadErrs) > 0 {
+		return errors.New(checked.loadErrs[0].Msg)
+	}
+	return errors.New("unknown reason")
+}
+
+// exprType returns the type of the type expression expr of a field declared in f
+func (finder *structFinder) exprType(expr ast.Expr, f *ast.File) (types.Type, error) {
+	checked := finder.filePackage(f)
+	var typ types.Type
+	if checked.pkg != nil {
+		typ = checked.info.TypeOf(expr)
+	}
+	if typ == nil || typ == types.Typ[types.Invalid] {
+		return nil, errors.New(fmt.Sprintln("Type", types.ExprString(expr), "can not be resolved:", checked.typeError(expr.Pos(), expr.End())))
+	}
+	return typ, nil
+}
+
+// basicType returns the basic type underlying the type expression expr of a field declared in f,
+// such as uint16 for a member of type VlanId declared as type VlanId uint16. It is empty if the
+// type is not a basic type, and an error if the type can not be resolved.
+func (finder *structFinder) basicType(expr ast.Expr, f *ast.File) (string, error) {
+	typ, err := finder.exprType(expr, f)
+	if err != nil {
+		return "", err
+	}
+	basic, ok := typ.Underlying().(*types.Basic)
+	if !ok || basic.Info()&(types.IsBoolean|types.IsNumeric|types.IsString) == 0 || basic.Info()&types.IsUntyped != 0 {
+		return "", nil
+	}
+	// byte and rune are named after the types they stand for
+	return types.Typ[basic.Kind()].Name(), nil
+}
+
+// defaultConst returns the value of the constant named by the DEFAULT value of a member declared
+// in f, a constant of the package of f or, if it is qualified, of a package imported by f. It is
+// nil if value does not name a constant. A default of a string member may be any text, so it is
+// only taken to name a constant if that exists and is a string constant, or if it is qualified
+// with the name of an imported package. Any other identifier but true and false given as the
+// default of another member has to name a constant that is not a string.
+func (finder *structFinder) defaultConst(value string, f *ast.File, isString bool) (constant.Value, error) {
+	parts := strings.SplitN(value, ".", 2)
+	for _, part := range parts {
+		if !token.IsIdentifier(part) {
+			return nil, nil
+		}
+	}
+	if value == "true" || value == "false" {
+		return nil, nil
+	}
+	checked := finder.filePackage(f)
+	if checked.pkg == nil {
+		return nil, errors.New(fmt.Sprintln("Default", value, "can not be resolved:", checked.typeError(f.Pos(), f.End())))
+	}
+	scope := checked.pkg.Scope()
+	name := value
+	if len(parts) == 2 {
+		pkgPath, exist := fileImports(f)[parts[0]]
+		if !exist {
+			if isString {
+				return nil, nil
+			}
+			return nil, errors.New(fmt.Sprintln("Package", parts[0], "of default", value, "is not imported"))
+		}
+		scope = nil
+		for _, imported := range checked.pkg.Imports() {
+			if imported.Path() == pkgPath {
+				scope = imported.Scope()
+			}
+		}
+		if scope == nil {
+			return nil, errors.New(fmt.Sprintln("Package", pkgPath, "of default", value, "can not be loaded:", checked.typeError(f.Pos(), f.End())))
+		}
+		name = parts[1]
+	}
+	c, ok := scope.Lookup(name).(*types.Const)
+	switch {
+	case ok && c.Val().Kind() != constant.Unknown && (c.Val().Kind() == constant.String) == isString:
+		return c.Val(), nil
+	case isString && len(parts) == 1:
+		// Plain text
+		return nil, nil
+	case ok && isString:
+		return nil, errors.New(fmt.Sprintln("Default", value, "is not a string constant"))
+	case ok && c.Val().Kind() == constant.String:
+		return nil, errors.New(fmt.Sprintln("Default", value, "is a string constant"))
+	}
+	return nil, errors.New(fmt.Sprintln("Default", value, "is not a constant"))
+}
+
+// constText returns the value of a constant the way it is written in a tag. Strings are unquoted.
+func constText(val constant.Value) string {
+	switch val.Kind() {
+	case constant.String:
+		return constant.StringVal(val)
+	case constant.Bool:
+		return strconv.FormatBool(constant.BoolVal(val))
+	case constant.Float:
+		fval, _ := constant.Float64Val(val)
+		return strconv.FormatFloat(fval, 'g', -1, 64)
+	}
+	return val.ExactString()
+}
+
+// checkAttrFields returns an error if the members of object objName, in the order of their
+// attribute ids, are not the fields the attrFields function of the generated code walks with
+// reflect. The []bool and AttrSet forms of the attributes are only interchangeable if the two
+// agree. typ is the declaration of the structure of the object in f.
+func (finder *structFinder) checkAttrFields(objName string, typ *ast.TypeSpec, f *ast.File, members []ObjectMemberAndInfo) error {
+	checked := finder.filePackage(f)
+	if checked.pkg == nil {
+		// Types that can not be resolved are reported with the members
+		return nil
+	}
+	def := checked.info.Defs[typ.Name]
+	if def == nil {
+		return nil
+	}
+	str, ok := def.Type().Underlying().(*types.Struct)
+	if !ok {
+		return nil
+	}
+	var names []string
+	for _, member := range members {
+		names = append(names, member.MemberName)
+	}
+	fields := attrFieldNames(str)
+	if strings.Join(names, ",") != strings.Join(fields, ",") {
+		return newError(finder.fset, typ.Pos(), "", objName, PHASE_MEMBERS, errors.New(fmt.Sprintln("Attributes",
+			strings.Join(names, ", "), "are not the fields", strings.Join(fields, ", "), "of the structure")))
+	}
+	return nil
+}
+
+// attrFieldNames returns the names of the fields of str as attrFields in the generated code sees
+// them: the fields of embedded structures take the place of the embedded field and other embedded
+// fields are left out
+func attrFieldNames(str *types.Struct) []string {
+	var names []string
+	for i := 0; i < str.NumFields(); i++ {
+		field := str.Field(i)
+		if !field.Anonymous() {
+			names = append(names, field.Name())
+			continue
+		}
+		if embedded, ok := field.Type().Underlying().(*types.Struct); ok {
+			names = append(names, attrFieldNames(embedded)...)
+		}
+	}
+	return names
+}
@@ -0,0 +1,238 @@
+package dbifgen
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/types"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Kinds of member types
+const (
+	TYPE_NAMED   = "named"
+	TYPE_POINTER = "pointer"
+	TYPE_SLICE   = "slice"
+	TYPE_ARRAY   = "array"
+	TYPE_MAP     = "map"
+	TYPE_STRUCT  = "struct"
+)
+
+// MemberType describes the go type of a member, as written in the model source
+type MemberType struct {
+	Kind string `json:"kind"`
+	// Name of a named type. Types of another package also have the name the package is imported
+	// as and its import path.
+	Name    string `json:"name,omitempty"`
+	Package string `json:"package,omitempty"`
+	PkgPath string `json:"pkgPath,omitempty"`
+	// Length of an array, a number or the name of a constant
+	Len string `json:"len,omitempty"`
+	// Key type of a map
+	Key *MemberType `json:"key,omitempty"`
+	// Element type of a pointer, slice, array or map
+	Elem *MemberType `json:"elem,omitempty"`
+	// Fields of a struct
+	Fields []MemberField `json:"fields,omitempty"`
+}
+
+// MemberField is a field of a struct member. Embedded fields have no name.
+type MemberField struct {
+	Name string      `json:"name,omitempty"`
+	Type *MemberType `json:"type"`
+	Tag  string      `json:"tag,omitempty"`
+}
+
+// String returns the go syntax of the type
+func (t *MemberType) String() string {
+	switch t.Kind {
+	case TYPE_NAMED:
+		if t.Package != "" {
+			return t.Package + "." + t.Name
+		}
+		return t.Name
+	case TYPE_POINTER:
+		return "*" + t.Elem.String()
+	case TYPE_SLICE:
+		return "[]" + t.Elem.String()
+	case TYPE_ARRAY:
+		return "[" + t.Len + "]" + t.Elem.String()
+	case TYPE_MAP:
+		return "map[" + t.Key.String() + "]" + t.Elem.String()
+	case TYPE_STRUCT:
+		var fields []string
+		for _, fld := range t.Fields {
+			field := fld.Type.String()
+			if fld.Name != "" {
+				field = fld.Name + " " + field
+			}
+			if fld.Tag != "" {
+				if strings.Contains(fld.Tag, "`") {
+					field += " " + strconv.Quote(fld.Tag)
+				} else {
+					field += " `" + fld.Tag + "`"
+				}
+			}
+			fields = append(fields, field)
+		}
+		if len(fields) == 0 {
+			return "struct{}"
+		}
+		return "struct { " + strings.Join(fields, "; ") + " }"
+	}
+	return ""
+}
+
+// packages adds the packages the type refers to, keyed by the name they are imported as, to pkgs
+func (t *MemberType) packages(pkgs map[string]string) {
+	if t == nil {
+		return
+	}
+	if t.Package != "" {
+		pkgs[t.Package] = t.PkgPath
+	}
+	t.Key.packages(pkgs)
+	t.Elem.packages(pkgs)
+	for _, fld := range t.Fields {
+		fld.Type.packages(pkgs)
+	}
+}
+
+// qualify makes the named types of t that are declared in the package pkgName, with import
+// path pkgPath, refer to that package. It is used for the members of structures of another
+// package embedded in an object.
+func (t *MemberType) qualify(pkgName string, pkgPath string) {
+	if t == nil {
+		return
+	}
+	if t.Kind == TYPE_NAMED && t.Package == "" && types.Universe.Lookup(t.Name) == nil {
+		t.Package, t.PkgPath = pkgName, pkgPath
+	}
+	t.Key.qualify(pkgName, pkgPath)
+	t.Elem.qualify(pkgName, pkgPath)
+	for _, fld := range t.Fields {
+		fld.Type.qualify(pkgName, pkgPath)
+	}
+}
+
+// fileImports returns the import paths of a source file keyed by the name the package is
+// imported as. Without an explicit name that is taken to be the last element of the path.
+func fileImports(f *ast.File) map[string]string {
+	imports := make(map[string]string)
+	for _, spec := range f.Imports {
+		pkgPath, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		name := path.Base(pkgPath)
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		if name != "_" && name != "." {
+			imports[name] = pkgPath
+		}
+	}
+	return imports
+}
+
+// newMemberType returns the description of the type expression expr of a member. imports are
+// the imports of the source file, used to resolve types of other packages.
+func newMemberType(expr ast.Expr, imports map[string]string) (*MemberType, error) {
+	switch typ := expr.(type) {
+	case *ast.Ident:
+		return &MemberType{Kind: TYPE_NAMED, Name: typ.Name}, nil
+	case *ast.ParenExpr:
+		return newMemberType(typ.X, imports)
+	case *ast.SelectorExpr:
+		pkg, ok := typ.X.(*ast.Ident)
+		if !ok {
+			break
+		}
+		pkgPath, exist := imports[pkg.Name]
+		if !exist {
+			return nil, errors.New(fmt.Sprintln("Package", pkg.Name, "of type", types.ExprString(typ), "is not imported"))
+		}
+		return &MemberType{Kind: TYPE_NAMED, Name: typ.Sel.Name, Package: pkg.Name, PkgPath: pkgPath}, nil
+	case *ast.StarExpr:
+		elem, err := newMemberType(typ.X, imports)
+		if err != nil {
+			return nil, err
+		}
+		return &MemberType{Kind: TYPE_POINTER, Elem: elem}, nil
+	case *ast.ArrayType:
+		elem, err := newMemberType(typ.Elt, imports)
+		if err != nil {
+			return nil, err
+		}
+		if typ.Len == nil {
+			return &MemberType{Kind: TYPE_SLICE, Elem: elem}, nil
+		}
+		return &MemberType{Kind: TYPE_ARRAY, Len: types.ExprString(typ.Len), Elem: elem}, nil
+	case *ast.MapType:
+		key, err := newMemberType(typ.Key, imports)
+		if err != nil {
+			return nil, err
+		}
+		elem, err := newMemberType(typ.Value, imports)
+		if err != nil {
+			return nil, err
+		}
+		return &MemberType{Kind: TYPE_MAP, Key: key, Elem: elem}, nil
+	case *ast.StructType:
+		t := &MemberType{Kind: TYPE_STRUCT}
+		for _, fld := range typ.Fields.List {
+			fldType, err := newMemberType(fld.Type, imports)
+			if err != nil {
+				return nil, err
+			}
+			var tag string
+			if fld.Tag != nil {
+				tag, _ = strconv.Unquote(fld.Tag.Value)
+			}
+			if fld.Names == nil {
+				t.Fields = append(t.Fields, MemberField{Type: fldType, Tag: tag})
+			}
+			for _, name := range fld.Names {
+				t.Fields = append(t.Fields, MemberField{Name: name.Name, Type: fldType, Tag: tag})
+			}
+		}
+		return t, nil
+	}
+	return nil, errors.New(fmt.Sprintln("Members of type", types.ExprString(expr), "are not supported"))
+}
+
+// storedAsJson reports whether the member is kept in the db as json text under a key of its own
+// instead of in the hash of the object. That is the case for slices of other than basic types and
+// for pointers, maps, structs and arrays.
+func (info ObjectMembersInfo) storedAsJson() bool {
+	if info.IsArray {
+		return !goBasicTypesMap[info.VarType]
+	}
+	return info.GoType != nil && info.GoType.Kind != TYPE_NAMED
+}
+
+// baseType returns the basic type the values of the member are handled as, VarType itself if that is
+// not a named type of a basic type
+func (info ObjectMembersInfo) baseType() string {
+	if info.BaseType != "" {
+		return info.BaseType
+	}
+	return info.VarType
+}
+
+// importLines returns the import lines of the packages, keyed by the name they are imported as
+func importLines(pkgs map[string]string) []string {
+	var lines []string
+	for name, pkgPath := range pkgs {
+		line := strconv.Quote(pkgPath)
+		if path.Base(pkgPath) != name {
+			line = name + " " + line
+		}
+		lines = append(lines, line+"\n")
+	}
+	sort.Strings(lines)
+	return lines
+}
@@ -0,0 +1,240 @@
+package dbifgen
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Selections of string members that are plain words. Anything else, such as the pattern pybind
+// writes for a YANG string with a pattern restriction, is not a list of values to check against.
+var plainSelection = regexp.MustCompile(`^[A-Za-z0-9_.:+-]+$`)
+
+// memberCheck is one condition a member value has to meet. cond is true when the value is
+// invalid, msg is the format of the message, given the value. Both use %[1]s for the value.
+type memberCheck struct {
+	cond string
+	msg  string
+}
+
+// WriteValidateFcn writes the Validate method of a config object. Validate checks the numeric
+// ranges, string lengths and selections given by the struct tags, and that key members are not
+// empty. Non key members without a default are optional, their zero value is not checked.
+func (obj *ObjectInfoJson) WriteValidateFcn(buf *bytes.Buffer, attrMap []ObjectMemberAndInfo, objMap map[string]ObjectInfoJson) error {
+	var lines []string
+	lines = append(lines, "\nfunc (obj "+obj.ObjName+") Validate() error {\n")
+	lines = append(lines, "verr := &ValidationError{Object: \""+obj.ObjName+"\"}\n")
+	for _, attrInfo := range attrMap {
+		checks, err := memberChecks(attrInfo)
+		if err != nil {
+			return errors.New(fmt.Sprintln("Member", attrInfo.MemberName+":", strings.TrimSpace(err.Error())))
+		}
+		if len(checks) == 0 {
+			continue
+		}
+		attrName := attrInfo.MemberName
+		if attrInfo.IsArray {
+			lines = append(lines, "for idx, val := range obj."+attrName+" {\n")
+			for _, check := range checks {
+				lines = append(lines, "if "+fmt.Sprintf(check.cond, "val")+" {\n",
+					"verr.add(fmt.Sprintf(\""+attrName+"[%d]\", idx), "+messageExpr(check.msg, "val")+")\n",
+					"}\n")
+			}
+			lines = append(lines, "}\n")
+			continue
+		}
+		optional := !attrInfo.IsKey && !attrInfo.IsDefaultSet
+		if optional {
+			lines = append(lines, "if obj."+attrName+" != "+zeroValue(attrInfo.baseType())+" {\n")
+		}
+		for _, check := range checks {
+			lines = append(lines, "if "+fmt.Sprintf(check.cond, "obj."+attrName)+" {\n",
+				"verr.add(\""+attrName+"\", "+messageExpr(check.msg, "obj."+attrName)+")\n",
+				"}\n")
+		}
+		if optional {
+			lines = append(lines, "}\n")
+		}
+	}
+	lines = append(lines, "return verr.Err()\n}\n")
+	for _, line := range lines {
+		buf.WriteString(line)
+	}
+	return nil
+}
+
+// memberChecks returns the checks of one member. Members of types other than string and the
+// numeric types are not checked.
+func memberChecks(attrInfo ObjectMemberAndInfo) ([]memberCheck, error) {
+	var checks []memberCheck
+	// The tag has been parsed once already when the model was loaded
+	tag, _ := parseTagLiteral(attrInfo.Tag)
+	minVal, hasMin := tag.Lookup("MIN")
+	maxVal, hasMax := tag.Lookup("MAX")
+	minVal, maxVal = strings.TrimSpace(minVal), strings.TrimSpace(maxVal)
+
+	switch {
+	case attrInfo.baseType() == "string":
+		if attrInfo.IsKey && !attrInfo.IsArray {
+			checks = append(checks, memberCheck{cond: `%[1]s == ""`, msg: "key must not be empty"})
+		}
+		// MIN and MAX of a string bound its length, LEN is its maximum length
+		if !hasMax && attrInfo.Len > 0 {
+			maxVal, hasMax = strconv.Itoa(attrInfo.Len), true
+		}
+		if hasMin || hasMax {
+			var r ValueRange
+			if hasMin {
+				r.Min = json.Number(minVal)
+			}
+			if hasMax {
+				r.Max = json.Number(maxVal)
+			}
+			if cond := rangesCond("len([]rune(%[1]s))", "uint", []ValueRange{r}); cond != "" {
+				checks = append(checks, memberCheck{cond: cond, msg: "length of %[1]q is out of range " + rangesText([]ValueRange{r})})
+			}
+		}
+		if len(attrInfo.Selections) > 0 {
+			var conds []string
+			for _, selection := range attrInfo.Selections {
+				if !plainSelection.MatchString(selection) {
+					conds = nil
+					break
+				}
+				conds = append(conds, "%[1]s != "+strconv.Quote(selection))
+			}
+			if len(conds) > 0 {
+				checks = append(checks, memberCheck{
+					cond: strings.Join(conds, " && "),
+					msg:  "%[1]q is not one of " + strings.Join(attrInfo.Selections, "/"),
+				})
+			}
+		}
+
+	case isIntegerType(attrInfo.baseType()) || attrInfo.baseType() == "float32" || attrInfo.baseType() == "float64":
+		ranges, err := numericRanges(attrInfo)
+		if err != nil {
+			return nil, err
+		}
+		if cond := rangesCond("%[1]s", attrInfo.baseType(), ranges); cond != "" {
+			checks = append(checks, memberCheck{cond: cond, msg: "%[1]v is out of range " + rangesText(ranges)})
+		}
+		if len(attrInfo.Selections) > 0 && isIntegerType(attrInfo.baseType()) {
+			var conds []string
+			for _, selection := range attrInfo.Selections {
+				match := intSelection.FindStringSubmatch(selection)
+				if match == nil || !defaultParses(attrInfo.baseType(), match[1]+match[2]) {
+					conds = nil
+					break
+				}
+				conds = append(conds, "%[1]s != "+match[1]+match[2])
+			}
+			if len(conds) > 0 {
+				checks = append(checks, memberCheck{
+					cond: strings.Join(conds, " && "),
+					msg:  "%[1]v is not one of " + strings.Join(attrInfo.Selections, "/"),
+				})
+			}
+		}
+	}
+	return checks, nil
+}
+
+// numericRanges returns the ranges of values allowed for a numeric member by its RANGE tag, or
+// else by its MIN and MAX tags
+func numericRanges(attrInfo ObjectMemberAndInfo) ([]ValueRange, error) {
+	ranges := attrInfo.Ranges
+	if len(ranges) == 0 {
+		tag, _ := parseTagLiteral(attrInfo.Tag)
+		minVal, hasMin := tag.Lookup("MIN")
+		maxVal, hasMax := tag.Lookup("MAX")
+		if hasMin || hasMax {
+			var r ValueRange
+			if hasMin {
+				r.Min = json.Number(strings.TrimSpace(minVal))
+			}
+			if hasMax {
+				r.Max = json.Number(strings.TrimSpace(maxVal))
+			}
+			ranges = []ValueRange{r}
+		}
+	}
+	for _, r := range ranges {
+		for _, bound := range []string{string(r.Min), string(r.Max)} {
+			if bound != "" && !defaultParses(attrInfo.baseType(), bound) {
+				return nil, errors.New(fmt.Sprintln("Bound", bound, "does not fit", attrInfo.baseType()))
+			}
+		}
+	}
+	return ranges, nil
+}
+
+// rangesCond returns the condition, on the value expression %[1]s of type varType, that is true
+// when the value is outside of all the ranges. It is empty if every value is within the ranges.
+func rangesCond(expr string, varType string, ranges []ValueRange) string {
+	var inRanges []string
+	for _, r := range ranges {
+		var bounds []string
+		// A lower bound of 0 does not restrict unsigned values
+		if low := rangeBound(r.Min); r.Min != "" && !(strings.HasPrefix(varType, "uint") && low != nil && low.Sign() <= 0) {
+			bounds = append(bounds, expr+" >= "+string(r.Min))
+		}
+		if r.Max != "" {
+			bounds = append(bounds, expr+" <= "+string(r.Max))
+		}
+		if len(bounds) == 0 {
+			return ""
+		}
+		inRanges = append(inRanges, strings.Join(bounds, " && "))
+	}
+	switch len(inRanges) {
+	case 0:
+		return ""
+	case 1:
+		return "!(" + inRanges[0] + ")"
+	}
+	return "!(" + strings.Join(inRanges, " || ") + ")"
+}
+
+// rangesText describes the ranges in a validation message, in the syntax of the RANGE tag
+func rangesText(ranges []ValueRange) string {
+	var texts []string
+	for _, r := range ranges {
+		low, high := string(r.Min), string(r.Max)
+		switch {
+		case low != "" && low == high:
+			texts = append(texts, low)
+			continue
+		case low == "":
+			low = "min"
+		case high == "":
+			high = "max"
+		}
+		texts = append(texts, low+"-"+high)
+	}
+	return strings.Join(texts, "|")
+}
+
+// messageExpr returns the go expression of the message msg for the value expression expr. The
+// value verb %[1] is shortened to % since the value is the only argument, any other % is escaped.
+func messageExpr(msg string, expr string) string {
+	if !strings.Contains(msg, "%[1]") {
+		return strconv.Quote(msg)
+	}
+	msg = strings.Replace(msg, "%", "%%", -1)
+	return "fmt.Sprintf(" + strconv.Quote(strings.Replace(msg, "%%[1]", "%", -1)) + ", " + expr + ")"
+}
+
+func zeroValue(varType string) string {
+	switch varType {
+	case "string":
+		return `""`
+	case "bool":
+		return "false"
+	}
+	return "0"
+}
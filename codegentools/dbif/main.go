@@ -0,0 +1,314 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/token"
+	"io/ioutil"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reltools/codegentools/dbif/dbifgen"
+	"sort"
+	"strings"
+)
+
+func main() {
+	args := os.Args[1:]
+	lint := len(args) > 0 && args[0] == "lint"
+	if lint {
+		args = args[1:]
+	}
+	opts, err := parseGenOptions(args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	logger, logFile, err := opts.newLogger()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if logFile != nil {
+		defer logFile.Close()
+	}
+	slog.SetDefault(logger)
+	dbifgen.SetLogger(logger)
+
+	if lint {
+		if !runLint(opts) {
+			os.Exit(1)
+		}
+		return
+	}
+
+	upToDate, err := run(opts)
+	if err != nil {
+		logErrors(err)
+		if opts.Check {
+			os.Exit(2)
+		}
+		os.Exit(1)
+	}
+	if !upToDate {
+		os.Exit(1)
+	}
+}
+
+// run generates all the files selected by opts. In check mode it reports whether the files on
+// disk are up to date, otherwise it always returns true on success.
+func run(opts GenOptions) (bool, error) {
+	fset := token.NewFileSet() // positions are relative to fset
+
+	// In dry-run, diff and check mode everything is generated in memory and compared with the disk
+	var out dbifgen.Output = dbifgen.FileOutput{}
+	var mem dbifgen.MemOutput
+	if opts.dryRun() {
+		mem = make(dbifgen.MemOutput)
+		out = mem
+	}
+
+	//
+	// Create a directory to store all the temporary files
+	//
+	dirStore := opts.GenInfoDir
+	if !opts.dryRun() {
+		if err := os.MkdirAll(dirStore, 0777); err != nil {
+			return false, errors.New(fmt.Sprintln("Failed to create directory", dirStore, err))
+		}
+	}
+
+	var gens []*dbifgen.Generator
+	var errs dbifgen.ErrorList
+	if opts.genObjects() {
+		gen, err := processConfigObjects(fset, out, opts.ObjectsDir, opts.ObjectsPkg, dirStore, opts)
+		if gen != nil {
+			gens = append(gens, gen)
+		}
+		errs.Add(err, "", "")
+	}
+	if opts.genActions() && (len(errs) == 0 || opts.KeepGoing) {
+		gen, err := processActionObjects(fset, out, opts.ActionsDir, opts.ActionsPkg, dirStore, opts)
+		if gen != nil {
+			gens = append(gens, gen)
+		}
+		errs.Add(err, "", "")
+	}
+	if len(errs) > 0 {
+		if !opts.dryRun() {
+			// Files written before the failure still need to be listed for cleangencode.sh
+			writeListing(opts.ListingFile, gens)
+		}
+		// Stale files are kept, they may belong to the objects that failed
+		return false, errs
+	}
+
+	stale, err := findStaleFiles(opts, gens)
+	if err != nil {
+		return false, errors.New(fmt.Sprintln("Failed to look for stale generated files", err))
+	}
+
+	if opts.dryRun() {
+		changes, err := dbifgen.CompareWithDisk(mem, stale)
+		if err != nil {
+			return false, errors.New(fmt.Sprintln("Failed to compare generated files", err))
+		}
+		if !opts.Check {
+			reportChanges(changes, opts.Diff)
+			return true, nil
+		}
+		unlisted, err := findUnlistedFiles(opts.ListingFile, gens)
+		if err != nil {
+			return false, err
+		}
+		return reportOutOfDate(changes, unlisted, opts.ListingFile), nil
+	}
+
+	if err = writeListing(opts.ListingFile, gens); err != nil {
+		slog.Error("Failed to update the listing", dbifgen.LOG_KEY_FILE, opts.ListingFile, dbifgen.LOG_KEY_ERROR, err)
+	}
+	for _, name := range stale {
+		slog.Info("Removing stale generated file", dbifgen.LOG_KEY_FILE, name)
+		if err := os.Remove(name); err != nil {
+			slog.Error("Failed to remove stale generated file", dbifgen.LOG_KEY_FILE, name, dbifgen.LOG_KEY_ERROR, err)
+		}
+	}
+	return true, nil
+}
+
+func processConfigObjects(fset *token.FileSet, out dbifgen.Output, objFileBase string, objectsPackage string, dirStore string, opts GenOptions) (*dbifgen.Generator, error) {
+	model, err := dbifgen.LoadObjects(fset, objFileBase)
+	return generate(model, err, out, objectsPackage, objFileBase, dirStore, dbifgen.OBJECT_INDEX_FILE, opts)
+}
+
+func processActionObjects(fset *token.FileSet, out dbifgen.Output, actionFileBase string, actionsPackage string, dirStore string, opts GenOptions) (*dbifgen.Generator, error) {
+	model, err := dbifgen.LoadActions(fset, actionFileBase)
+	return generate(model, err, out, actionsPackage, actionFileBase, dirStore, dbifgen.ACTION_INDEX_FILE, opts)
+}
+
+// generate runs the generator on a loaded model. With KeepGoing, a model that only loaded partly
+// is still generated, but no index file is written from it. Files whose inputs have not
+// changed are only left alone when writing to disk: dry-run, diff and check mode compare every file.
+func generate(model *dbifgen.Model, loadErr error, out dbifgen.Output, packageName string, srcDir string, dirStore string,
+	indexFile string, opts GenOptions) (*dbifgen.Generator, error) {
+	if model == nil || (loadErr != nil && !opts.KeepGoing) {
+		return nil, loadErr
+	}
+	gen := dbifgen.NewGenerator(out, packageName, srcDir, dirStore)
+	gen.KeepGoing = opts.KeepGoing
+	gen.Jobs = opts.Jobs
+	if !opts.dryRun() {
+		gen.ManifestFile = filepath.Join(dirStore, packageName+dbifgen.MANIFEST_FILE_SUFFIX)
+		gen.Force = opts.Force
+	}
+	if loadErr == nil {
+		gen.IndexFile = filepath.Join(srcDir, indexFile)
+	}
+	var errs dbifgen.ErrorList
+	errs.Add(loadErr, "", dbifgen.PHASE_LOAD)
+	errs.Add(gen.Generate(model), "", "")
+	return gen, errs.Err()
+}
+
+// runLint checks the models selected by opts and prints the findings. It returns false if any
+// of the findings is an error.
+func runLint(opts GenOptions) bool {
+	fset := token.NewFileSet()
+	var findings []dbifgen.LintFinding
+	if opts.genObjects() {
+		model, err := dbifgen.LoadObjects(fset, opts.ObjectsDir)
+		findings = append(findings, dbifgen.LintErrors(err)...)
+		if model != nil {
+			findings = append(findings, dbifgen.Lint(fset, model)...)
+		}
+	}
+	if opts.genActions() {
+		model, err := dbifgen.LoadActions(fset, opts.ActionsDir)
+		findings = append(findings, dbifgen.LintErrors(err)...)
+		if model != nil {
+			findings = append(findings, dbifgen.Lint(fset, model)...)
+		}
+	}
+	clean := true
+	for _, finding := range findings {
+		fmt.Println(finding)
+		if finding.Severity == dbifgen.LINT_ERROR {
+			clean = false
+		}
+	}
+	return clean
+}
+
+// logErrors logs every failure of the run, with its location when it is known
+func logErrors(err error) {
+	list, ok := err.(dbifgen.ErrorList)
+	if !ok {
+		slog.Error("Generation failed", dbifgen.LOG_KEY_ERROR, err)
+		return
+	}
+	for _, e := range list {
+		slog.Error(e.Error(), dbifgen.LOG_KEY_PHASE, e.Phase)
+	}
+	slog.Error("Generation failed", "errors", len(list))
+}
+
+// findStaleFiles returns the previously generated files that this run no longer produces. The genInfo
+// directory is shared by objects and actions, so it is only looked at when both are generated.
+func findStaleFiles(opts GenOptions, gens []*dbifgen.Generator) ([]string, error) {
+	generated := make(map[string]bool)
+	for _, gen := range gens {
+		for _, name := range gen.GeneratedFiles() {
+			generated[name] = true
+		}
+	}
+	var stale []string
+	for _, gen := range gens {
+		files, err := dbifgen.FindStaleFiles(gen.SrcDir, dbifgen.GeneratedSrcPatterns, generated)
+		if err != nil {
+			return nil, err
+		}
+		stale = append(stale, files...)
+	}
+	if opts.Only == GEN_ALL {
+		files, err := dbifgen.FindStaleFiles(opts.GenInfoDir, dbifgen.GeneratedInfoPatterns, generated)
+		if err != nil {
+			return nil, err
+		}
+		stale = append(stale, files...)
+	}
+	return stale, nil
+}
+
+func reportChanges(changes []dbifgen.FileChange, showDiff bool) {
+	counts := make(map[string]int)
+	for _, change := range changes {
+		counts[change.Kind]++
+		if showDiff {
+			fmt.Print(change.Diff())
+		} else {
+			fmt.Printf("%-7s %s\n", change.Kind, change.Name)
+		}
+	}
+	fmt.Fprintf(os.Stderr, "%d to create, %d to update, %d to delete\n",
+		counts[dbifgen.CHANGE_CREATE], counts[dbifgen.CHANGE_UPDATE], counts[dbifgen.CHANGE_DELETE])
+}
+
+// reportOutOfDate lists the files that differ from what the generator produces and the generated
+// go files missing from the listing file. It returns true if there is nothing to report.
+func reportOutOfDate(changes []dbifgen.FileChange, unlisted []string, listingFile string) bool {
+	for _, change := range changes {
+		fmt.Printf("%-7s %s\n", change.Kind, change.Name)
+	}
+	for _, name := range unlisted {
+		fmt.Printf("%-7s %s\n", "unlisted", name)
+	}
+	if len(changes) == 0 && len(unlisted) == 0 {
+		return true
+	}
+	fmt.Fprintf(os.Stderr, "Generated files are out of date: %d to update, %d missing from %s. Run gencode.sh\n",
+		len(changes), len(unlisted), listingFile)
+	return false
+}
+
+// findUnlistedFiles returns the go files generated by gens that are not in the listing file.
+// The listing file is shared with the other generators, so only its coverage is checked.
+func findUnlistedFiles(listingFile string, gens []*dbifgen.Generator) ([]string, error) {
+	listed := make(map[string]bool)
+	data, err := ioutil.ReadFile(listingFile)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, errors.New(fmt.Sprintln("Failed to read the file", listingFile, err))
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		listed[strings.TrimSpace(line)] = true
+	}
+	var unlisted []string
+	for _, gen := range gens {
+		for _, goFile := range gen.GeneratedGoFiles() {
+			if !listed[goFile] {
+				unlisted = append(unlisted, goFile)
+			}
+		}
+	}
+	sort.Strings(unlisted)
+	return unlisted, nil
+}
+
+// writeListing appends the go files generated by gens to the listing file. The listing is shared
+// with the other generators, so the file is read and replaced as a whole with the lines added.
+func writeListing(listingFile string, gens []*dbifgen.Generator) error {
+	data, err := ioutil.ReadFile(listingFile)
+	if err != nil && !os.IsNotExist(err) {
+		return errors.New(fmt.Sprintln("Failed to read the file", listingFile, err))
+	}
+	listing := bytes.NewBuffer(data)
+	for _, gen := range gens {
+		for _, goFile := range gen.GeneratedGoFiles() {
+			listing.WriteString(goFile + "\n")
+		}
+	}
+	if err = dbifgen.WriteFileAtomic(listingFile, listing.Bytes()); err != nil {
+		return errors.New(fmt.Sprintln("Failed to write the file", listingFile, err))
+	}
+	return nil
+}
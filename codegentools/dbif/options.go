@@ -0,0 +1,200 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log/slog"
+	"os"
+	"path/filepath"
+)
+
+const (
+	GEN_ALL     = "all"
+	GEN_OBJECTS = "objects"
+	GEN_ACTIONS = "actions"
+)
+
+// This structure holds the locations the generator reads models from and writes generated files to.
+// It can be populated from a json config file (-config) and/or from command line flags. Flags
+// that are set explicitly take precedence over the config file.
+type GenOptions struct {
+	ObjectsDir  string `json:"objectsDir"`
+	ActionsDir  string `json:"actionsDir"`
+	GenInfoDir  string `json:"genInfoDir"`
+	ListingFile string `json:"listingFile"`
+	ObjectsPkg  string `json:"objectsPkg"`
+	ActionsPkg  string `json:"actionsPkg"`
+	Only        string `json:"only"`
+	LogLevel    string `json:"logLevel"`
+	LogFile     string `json:"logFile"`
+	DryRun      bool   `json:"-"`
+	Diff        bool   `json:"-"`
+	Check       bool   `json:"-"`
+	Force       bool   `json:"-"`
+	KeepGoing   bool   `json:"keepGoing"`
+	Jobs        int    `json:"jobs"`
+}
+
+// Default locations are derived from SR_CODE_BASE so that gencode.sh keeps working unchanged
+func defaultGenOptions() GenOptions {
+	opts := GenOptions{
+		ObjectsPkg: "objects",
+		ActionsPkg: "actions",
+		Only:       GEN_ALL,
+		LogLevel:   "info",
+	}
+	base := os.Getenv("SR_CODE_BASE")
+	if len(base) > 0 {
+		opts.ObjectsDir = filepath.Join(base, "snaproute/src/models/objects")
+		opts.ActionsDir = filepath.Join(base, "snaproute/src/models/actions")
+		opts.GenInfoDir = filepath.Join(base, "reltools/codegentools/._genInfo")
+	}
+	return opts
+}
+
+func parseGenOptions(args []string) (GenOptions, error) {
+	opts := defaultGenOptions()
+	var cfgFile string
+	var flagOpts GenOptions
+
+	flags := flag.NewFlagSet("dbif", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: dbif [lint] [options]")
+		fmt.Fprintln(flags.Output(), "  lint checks the models for mistakes instead of generating code")
+		flags.PrintDefaults()
+	}
+	flags.StringVar(&cfgFile, "config", "", "json file with generator options (keys: objectsDir, actionsDir, genInfoDir, listingFile, objectsPkg, actionsPkg, only, logLevel, logFile, keepGoing, jobs)")
+	flags.StringVar(&flagOpts.ObjectsDir, "objects-dir", opts.ObjectsDir, "directory holding config object models, genObjectConfig.json and goObjInfo.json")
+	flags.StringVar(&flagOpts.ActionsDir, "actions-dir", opts.ActionsDir, "directory holding action models, genObjectAction.json and goActionInfo.json")
+	flags.StringVar(&flagOpts.GenInfoDir, "geninfo-dir", opts.GenInfoDir, "directory for Members.json and extschema files")
+	flags.StringVar(&flagOpts.ListingFile, "listing-file", "", "file the list of generated go files is appended to (default <geninfo-dir>/generatedGoFiles.txt)")
+	flags.StringVar(&flagOpts.ObjectsPkg, "objects-pkg", opts.ObjectsPkg, "package name of the generated config object code")
+	flags.StringVar(&flagOpts.ActionsPkg, "actions-pkg", opts.ActionsPkg, "package name of the generated action code")
+	flags.StringVar(&flagOpts.Only, "only", opts.Only, "generate only \""+GEN_OBJECTS+"\" or only \""+GEN_ACTIONS+"\" instead of \""+GEN_ALL+"\"")
+	flags.StringVar(&flagOpts.LogLevel, "log-level", opts.LogLevel, "log messages of this level and above: debug, info, warn or error")
+	flags.StringVar(&flagOpts.LogFile, "log-file", "", "file log messages are appended to (default stderr)")
+	flags.BoolVar(&flagOpts.KeepGoing, "keep-going", false, "go on with the other objects when one fails and report all failures at the end")
+	flags.IntVar(&flagOpts.Jobs, "jobs", 0, "number of objects generated at the same time (default the number of CPUs)")
+	flags.BoolVar(&opts.DryRun, "dry-run", false, "list the files that would be created, updated or deleted without writing anything")
+	flags.BoolVar(&opts.Diff, "diff", false, "like -dry-run, but print a unified diff of every file that would change")
+	flags.BoolVar(&opts.Force, "force", false, "generate all files, even those whose inputs are unchanged since the last run")
+	flags.BoolVar(&opts.Check, "check", false, "exit with status 1 and list the out of date files if the generated files on disk differ from the models")
+	if err := flags.Parse(args); err != nil {
+		return opts, err
+	}
+	if flags.NArg() > 0 {
+		return opts, errors.New(fmt.Sprintln("Unexpected arguments", flags.Args()))
+	}
+
+	if cfgFile != "" {
+		bytes, err := ioutil.ReadFile(cfgFile)
+		if err != nil {
+			return opts, err
+		}
+		if err = json.Unmarshal(bytes, &opts); err != nil {
+			return opts, errors.New(fmt.Sprintln("Error in unmarshaling data from", cfgFile, err))
+		}
+	}
+	flags.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "objects-dir":
+			opts.ObjectsDir = flagOpts.ObjectsDir
+		case "actions-dir":
+			opts.ActionsDir = flagOpts.ActionsDir
+		case "geninfo-dir":
+			opts.GenInfoDir = flagOpts.GenInfoDir
+		case "listing-file":
+			opts.ListingFile = flagOpts.ListingFile
+		case "objects-pkg":
+			opts.ObjectsPkg = flagOpts.ObjectsPkg
+		case "actions-pkg":
+			opts.ActionsPkg = flagOpts.ActionsPkg
+		case "only":
+			opts.Only = flagOpts.Only
+		case "log-level":
+			opts.LogLevel = flagOpts.LogLevel
+		case "log-file":
+			opts.LogFile = flagOpts.LogFile
+		case "keep-going":
+			opts.KeepGoing = flagOpts.KeepGoing
+		case "jobs":
+			opts.Jobs = flagOpts.Jobs
+		}
+	})
+	return opts, opts.validate()
+}
+
+func (opts *GenOptions) validate() error {
+	if opts.GenInfoDir == "" {
+		return errors.New("genInfo directory is not set. Use -geninfo-dir or set SR_CODE_BASE")
+	}
+	if opts.ListingFile == "" {
+		opts.ListingFile = filepath.Join(opts.GenInfoDir, "generatedGoFiles.txt")
+	}
+	switch opts.Only {
+	case "", GEN_ALL:
+		opts.Only = GEN_ALL
+	case GEN_OBJECTS, GEN_ACTIONS:
+	default:
+		return errors.New(fmt.Sprintln("Invalid value for -only:", opts.Only))
+	}
+	if opts.Jobs < 0 {
+		return errors.New(fmt.Sprintln("Invalid value for -jobs:", opts.Jobs))
+	}
+	if opts.LogLevel == "" {
+		opts.LogLevel = "info"
+	}
+	if _, err := opts.logLevel(); err != nil {
+		return err
+	}
+	if opts.genObjects() && opts.ObjectsDir == "" {
+		return errors.New("Objects directory is not set. Use -objects-dir or set SR_CODE_BASE")
+	}
+	if opts.genActions() && opts.ActionsDir == "" {
+		return errors.New("Actions directory is not set. Use -actions-dir or set SR_CODE_BASE")
+	}
+	return nil
+}
+
+func (opts *GenOptions) logLevel() (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(opts.LogLevel)); err != nil {
+		return level, errors.New(fmt.Sprintln("Invalid value for -log-level:", opts.LogLevel))
+	}
+	return level, nil
+}
+
+// newLogger returns the logger selected by the log options and the file it writes to, if any,
+// which the caller has to close
+func (opts *GenOptions) newLogger() (*slog.Logger, io.Closer, error) {
+	level, err := opts.logLevel()
+	if err != nil {
+		return nil, nil, err
+	}
+	handlerOpts := &slog.HandlerOptions{Level: level}
+	if opts.LogFile == "" {
+		return slog.New(slog.NewTextHandler(os.Stderr, handlerOpts)), nil, nil
+	}
+	f, err := os.OpenFile(opts.LogFile, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0660)
+	if err != nil {
+		return nil, nil, errors.New(fmt.Sprintln("Failed to open the log file", opts.LogFile, err))
+	}
+	return slog.New(slog.NewTextHandler(f, handlerOpts)), f, nil
+}
+
+// Nothing is written to disk in dry-run, diff and check mode
+func (opts *GenOptions) dryRun() bool {
+	return opts.DryRun || opts.Diff || opts.Check
+}
+
+func (opts *GenOptions) genObjects() bool {
+	return opts.Only == GEN_ALL || opts.Only == GEN_OBJECTS
+}
+
+func (opts *GenOptions) genActions() bool {
+	return opts.Only == GEN_ALL || opts.Only == GEN_ACTIONS
+}
@@ -4,6 +4,10 @@ import re
 
 OBJECT_MAP_NAME = "gen_objMap.go"
 ACTION_MAP_NAME = "gen_actionMap.go"
+# Written by the dbif generator: the objects of genObjectConfig.json/genObjectAction.json together
+# with the hand coded ones, and the flags and links derived from the models
+OBJECT_INDEX_NAME = "genObjectConfigIndex.json"
+ACTION_INDEX_NAME = "genObjectActionIndex.json"
 
 OBJECTS_NAME = 'objects'
 ACTIONS_NAME = 'actions'
@@ -897,8 +901,8 @@ func CheckIfV4RouteKeyExists(thriftobj *ribd.IPv4Route, dbHdl *dbutils.DBUtil) (
 gDryRun =  False
 def generateThriftAndClientIfs():
     # generate thrift code from go code
-    genObjInfoJson = JSON_MODEL_REGISTRAION_PATH + 'genObjectConfig.json'
-    genActionInfoJson = JSON_ACTION_REGISTRAION_PATH + 'genObjectAction.json'
+    genObjInfoJson = JSON_MODEL_REGISTRAION_PATH + OBJECT_INDEX_NAME
+    genActionInfoJson = JSON_ACTION_REGISTRAION_PATH + ACTION_INDEX_NAME
     goDmnDirsInfoJson = JSON_MODEL_REGISTRAION_PATH + 'goObjInfo.json'
     yangDmnDirsInfoJson = JSON_MODEL_REGISTRAION_PATH + 'yangObjInfo.json'
     goActionDmnDirsInfoJson = JSON_ACTION_REGISTRAION_PATH + 'goActionInfo.json'
@@ -955,7 +959,7 @@ def generateThriftAndClientIfs():
 
 
 def generateConfigObjectMap():
-    genObjInfoJson = JSON_MODEL_REGISTRAION_PATH + 'genObjectConfig.json'
+    genObjInfoJson = JSON_MODEL_REGISTRAION_PATH + OBJECT_INDEX_NAME
     fd = open(OBJMAP_CODE_GENERATION_PATH + OBJECT_MAP_NAME, 'w+')
     fd.write("""package %s\n\n""" % OBJECTS_NAME)
     fd.write("""var GenConfigObjectMap = map[string] ConfigObj{\n""")
@@ -975,7 +979,7 @@ def generateConfigObjectMap():
 
 
 def generateActionObjectMap():
-    genActionInfoJson = JSON_ACTION_REGISTRAION_PATH + 'genObjectAction.json'
+    genActionInfoJson = JSON_ACTION_REGISTRAION_PATH + ACTION_INDEX_NAME
     fd = open(ACTIONMAP_CODE_GENERATION_PATH + ACTION_MAP_NAME, 'w+')
     fd.write("""package %s\n\n""" % ACTIONS_NAME)
     fd.write("""var GenActionObjectMap = map[string] ActionObj{\n""")
@@ -7,6 +7,7 @@ import (
 	"io/ioutil"
 	"models/objects"
 	"os"
+	"sort"
 	"strings"
 )
 
@@ -62,6 +63,7 @@ type StructDetails struct {
 	AutoDiscover bool
 	AutoCreate   bool
 	Multiplicity bool
+	Position     int
 }
 
 type DaemonDetail map[string][]StructDetails
@@ -77,6 +79,7 @@ type ParameterDetails struct {
 	Selection    []string `json:"selections"`
 	AutoDiscover bool     `json:"autoDiscover"`
 	AutoCreate   bool     `json:"autoCreate"`
+	Position     int      `json:"position"`
 }
 
 func generateParameterDetailList(structName string, owner string, Multiplicity string) {
@@ -106,6 +109,7 @@ func generateParameterDetailList(structName string, owner string, Multiplicity s
 			Default:      val.Default,
 			AutoDiscover: val.AutoDiscover,
 			AutoCreate:   val.AutoCreate,
+			Position:     val.Position,
 		}
 		if val.Selection != nil {
 			structDetail.Selection = append(structDetail.Selection, val.Selection...)
@@ -115,6 +119,13 @@ func generateParameterDetailList(structName string, owner string, Multiplicity s
 		}
 		structDetails = append(structDetails, structDetail)
 	}
+	// List the parameters in the order they are declared in the model
+	sort.Slice(structDetails, func(i, j int) bool {
+		if structDetails[i].Position != structDetails[j].Position {
+			return structDetails[i].Position < structDetails[j].Position
+		}
+		return structDetails[i].FieldName < structDetails[j].FieldName
+	})
 	ModelObjEnt[structName] = structDetails
 	ModelObj[owner] = ModelObjEnt
 }
@@ -174,7 +185,13 @@ func generateModelObjectRstFile(listOfDaemon *map[string]bool) {
 	f.WriteString(".. toctree::\n")
 	f.WriteString("   :maxdepth: 1\n\n")
 
-	for key, objList := range GoObjectMap {
+	classes := make([]string, 0, len(GoObjectMap))
+	for key := range GoObjectMap {
+		classes = append(classes, key)
+	}
+	sort.Strings(classes)
+	for _, key := range classes {
+		objList := GoObjectMap[key]
 		rstFile := key + "Objects.rst"
 		f.WriteString("   " + key + " Objects  <" + rstFile + ">\n")
 		fp, err := os.Create("rstFiles/" + key + "Objects.rst")
@@ -498,7 +515,7 @@ func main() {
 	var objMap map[string]ConfigObjJson
 
 	objMap = make(map[string]ConfigObjJson)
-	objConfigFile := "../../snaproute/src/models/objects/genObjectConfig.json"
+	objConfigFile := "../../snaproute/src/models/objects/genObjectConfigIndex.json"
 
 	bytes, err := ioutil.ReadFile(objConfigFile)
 	if err != nil {